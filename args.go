@@ -0,0 +1,76 @@
+package botkit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	errUnterminatedQuote  = fmt.Errorf("unterminated quote")
+	errUnterminatedEscape = fmt.Errorf("unterminated escape sequence")
+)
+
+// splitArgs splits a command line into shell-like arguments.
+// Splitting stops after n arguments (n < 0 means no limit) and the rest of the line is returned untouched.
+func splitArgs(s string, n int) (args []string, rest string, err error) {
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if len(s) == 0 {
+			return args, "", nil
+		}
+		if n >= 0 && len(args) >= n {
+			return args, strings.TrimRightFunc(s, unicode.IsSpace), nil
+		}
+		var arg string
+		arg, s, err = nextArg(s)
+		if err != nil {
+			return nil, "", err
+		}
+		args = append(args, arg)
+	}
+}
+
+func nextArg(s string) (arg string, rest string, err error) {
+	var sb strings.Builder
+	var quote rune
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '\\' && quote != '\'':
+			if i >= len(s) {
+				return "", "", errUnterminatedEscape
+			}
+			next, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			if quote == '"' && next != '"' && next != '\\' {
+				sb.WriteRune(r)
+			}
+			sb.WriteRune(next)
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			return sb.String(), s[i:], nil
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return "", "", errUnterminatedQuote
+	}
+	return sb.String(), "", nil
+}
@@ -0,0 +1,51 @@
+package botkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		args []string
+		rest string
+		err  error
+	}{
+		{in: "", n: -1},
+		{in: "   ", n: -1},
+		{in: "a b  c", n: -1, args: []string{"a", "b", "c"}},
+		{in: " \ta\n b ", n: -1, args: []string{"a", "b"}},
+		{in: `"a b" c`, n: -1, args: []string{"a b", "c"}},
+		{in: `'a b' c`, n: -1, args: []string{"a b", "c"}},
+		{in: `a"b c"d`, n: -1, args: []string{"ab cd"}},
+		{in: `"" ''`, n: -1, args: []string{"", ""}},
+		{in: `"it's"`, n: -1, args: []string{"it's"}},
+		{in: `'say "hi"'`, n: -1, args: []string{`say "hi"`}},
+		{in: `a\ b`, n: -1, args: []string{"a b"}},
+		{in: `\"a\'`, n: -1, args: []string{`"a'`}},
+		{in: `"a\"b\\c"`, n: -1, args: []string{`a"b\c`}},
+		{in: `"a\nb"`, n: -1, args: []string{`a\nb`}},
+		{in: `'a\b'`, n: -1, args: []string{`a\b`}},
+		{in: "héllo wörld", n: -1, args: []string{"héllo", "wörld"}},
+		{in: `"a b`, n: -1, err: errUnterminatedQuote},
+		{in: `'a b`, n: -1, err: errUnterminatedQuote},
+		{in: `a\`, n: -1, err: errUnterminatedEscape},
+		{in: `"a\`, n: -1, err: errUnterminatedEscape},
+		{in: "a b c d", n: 2, args: []string{"a", "b"}, rest: "c d"},
+		{in: `a  "b c"  d  `, n: 1, args: []string{"a"}, rest: `"b c"  d`},
+		{in: "a b", n: 0, rest: "a b"},
+		{in: `a "b`, n: 1, args: []string{"a"}, rest: `"b`},
+	}
+	for _, tt := range tests {
+		args, rest, err := splitArgs(tt.in, tt.n)
+		if err != tt.err {
+			t.Errorf("splitArgs(%q, %d) error = %v, want %v", tt.in, tt.n, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(args, tt.args) || rest != tt.rest {
+			t.Errorf("splitArgs(%q, %d) = %q, %q, want %q, %q", tt.in, tt.n, args, rest, tt.args, tt.rest)
+		}
+	}
+}
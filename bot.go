@@ -1,32 +1,50 @@
 package botkit
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/rand"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/razzie/commander"
+	"github.com/razzie/botkit/format"
 	"github.com/razzie/razcache"
 )
 
 type Bot struct {
 	BotOptions
-	token string
-	cache razcache.Cache
-	api   *tgbotapi.BotAPI
-	rand  rand.Rand
+	token         string
+	cache         razcache.Cache
+	api           *tgbotapi.BotAPI
+	ctx           context.Context
+	cancel        context.CancelFunc
+	disp          *dispatcher
+	throttler     *sendThrottler
+	sendQueue     *sendQueue
+	offsets       *offsetTracker
+	handler       Handler
+	mu            sync.Mutex
+	server        *http.Server
+	closed        bool
+	rateLimitMu   sync.Mutex
+	registryMu    sync.Mutex
+	recentChats   map[int64]time.Time
+	registrySwept time.Time
 }
 
 func NewBot(token string, opts ...BotOption) (*Bot, error) {
 	bot := &Bot{
-		BotOptions: defaultOptions,
-		token:      token,
-		rand:       *rand.New(rand.NewSource(time.Now().Unix())),
+		BotOptions:  defaultOptions,
+		token:       token,
+		recentChats: make(map[int64]time.Time),
 	}
 	for _, opt := range opts {
 		opt(&bot.BotOptions)
@@ -47,40 +65,226 @@ func NewBot(token string, opts ...BotOption) (*Bot, error) {
 		bot.cache = razcache.NewInMemCache()
 	}
 
+	bot.addHelpCommand()
+	bot.ctx, bot.cancel = context.WithCancel(context.Background())
+	bot.offsets = newOffsetTracker(bot.loadOffset())
+	bot.handler = bot.routeUpdate
+	for i := len(bot.middlewares) - 1; i >= 0; i-- {
+		bot.handler = bot.middlewares[i](bot.handler)
+	}
+	bot.throttler = newSendThrottler(bot.globalSendRateLimit, bot.chatSendRateLimit, bot.groupSendRateLimit)
+	bot.sendQueue = newSendQueue()
+	bot.disp = newDispatcher(bot.ctx, bot.workers, bot.handleUpdate)
+
 	return bot, nil
 }
 
 func (bot *Bot) Run() {
-	updateConfig := tgbotapi.NewUpdate(bot.offset)
+	offset := bot.offset
+	if committed := bot.offsets.lastCommitted(); committed >= offset {
+		offset = committed + 1
+	}
+	bot.setCommandMenu()
+
+	updateConfig := tgbotapi.NewUpdate(offset)
 	updateConfig.Timeout = bot.timeout
+	updateConfig.AllowedUpdates = bot.getAllowedUpdates()
 
 	for update := range bot.api.GetUpdatesChan(updateConfig) {
-		if msg := update.Message; msg != nil {
-			if update.Message.IsCommand() {
-				bot.handleCommand(msg)
-			} else if len(msg.Text) > 0 {
-				bot.handleMessage(msg)
-			} else if fileIDs := getFileIDsFromMessage(msg); len(fileIDs) > 0 {
-				for _, fileID := range fileIDs {
-					bot.handleFile(msg, fileID)
-				}
-			}
-		}
-		if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
-			bot.handleCallback(update.CallbackQuery)
-		}
+		bot.dispatchUpdate(update)
 	}
 }
 
 func (bot *Bot) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), bot.shutdownTimeout)
+	defer cancel()
+	return bot.Shutdown(ctx)
+}
+
+func (bot *Bot) Shutdown(ctx context.Context) error {
+	bot.mu.Lock()
+	if bot.closed {
+		bot.mu.Unlock()
+		return nil
+	}
+	bot.closed = true
+	srv := bot.server
+	bot.mu.Unlock()
+
 	bot.api.StopReceivingUpdates()
-	return nil
+	var errs []error
+	if srv != nil {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	bot.disp.stop()
+	if err := bot.disp.wait(ctx); err != nil {
+		bot.logger.Warn("shutdown deadline exceeded with handlers still running")
+		errs = append(errs, err)
+	}
+	bot.cancel()
+	bot.saveOffset(bot.offsets.lastCommitted())
+	return errors.Join(errs...)
 }
 
 func (bot *Bot) GetChat(chatID int64) Chat {
 	return newChat(bot, chatID)
 }
 
+// dispatchUpdate returns false if the update could not be queued and should be delivered again
+func (bot *Bot) dispatchUpdate(update tgbotapi.Update) bool {
+	if !bot.offsets.begin(update.UpdateID) {
+		bot.logger.Debug("skipping already processed update", slog.Int("updateID", update.UpdateID))
+		return true
+	}
+	if !bot.disp.dispatch(update) {
+		bot.logger.Warn("update dropped after close", slog.Int("updateID", update.UpdateID))
+		return false
+	}
+	return true
+}
+
+func (bot *Bot) handleUpdate(workerCtx context.Context, update tgbotapi.Update) {
+	defer bot.commitUpdate(update.UpdateID)
+
+	bot.trackChat(update)
+
+	parent, cancel := bot.newUpdateContext(workerCtx)
+	defer cancel()
+
+	var ctx *Context
+	switch {
+	case update.Message != nil:
+		ctx = newContext(parent, bot, update.Message)
+	case update.EditedMessage != nil:
+		ctx = newContext(parent, bot, update.EditedMessage)
+	case update.ChannelPost != nil:
+		ctx = newContext(parent, bot, update.ChannelPost)
+	case update.EditedChannelPost != nil:
+		ctx = newContext(parent, bot, update.EditedChannelPost)
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		ctx = newCallbackContext(parent, bot, update.CallbackQuery)
+	case update.MyChatMember != nil:
+		ctx = newChatMemberContext(parent, bot, update.MyChatMember)
+	case update.ChatMember != nil:
+		ctx = newChatMemberContext(parent, bot, update.ChatMember)
+	case update.ShippingQuery != nil:
+		ctx = newUserContext(parent, bot, update.ShippingQuery.From)
+	case update.PreCheckoutQuery != nil:
+		ctx = newUserContext(parent, bot, update.PreCheckoutQuery.From)
+	case update.PollAnswer != nil:
+		ctx = newUserContext(parent, bot, &update.PollAnswer.User)
+	case update.InlineQuery != nil:
+		ctx = newUserContext(parent, bot, update.InlineQuery.From)
+	case update.ChosenInlineResult != nil:
+		ctx = newUserContext(parent, bot, update.ChosenInlineResult.From)
+	default:
+		return
+	}
+	if err := bot.handler(ctx, update); err != nil {
+		bot.logger.Error("update handler error", slogContext(ctx), slog.Any("err", err))
+	}
+}
+
+func (bot *Bot) routeUpdate(ctx *Context, update tgbotapi.Update) error {
+	if msg := update.Message; msg != nil {
+		if msg.SuccessfulPayment != nil {
+			return bot.handlePayment(ctx, msg)
+		} else if msg.IsCommand() {
+			bot.handleCommand(ctx, msg)
+		} else if len(msg.Text) > 0 {
+			return bot.handleMessage(ctx, msg)
+		} else if fileIDs := getFileIDsFromMessage(msg); len(fileIDs) > 0 {
+			for _, fileID := range fileIDs {
+				bot.handleFile(ctx, msg, fileID)
+			}
+		}
+	}
+	if msg := update.EditedMessage; msg != nil {
+		if msg.IsCommand() && bot.editedCommands {
+			bot.handleCommand(ctx, msg)
+		} else if bot.editedMsgHandler != nil {
+			return bot.editedMsgHandler(ctx, getMessageText(msg))
+		}
+	}
+	if msg := update.ChannelPost; msg != nil {
+		if msg.IsCommand() && bot.channelPostCommands {
+			bot.handleCommand(ctx, msg)
+		} else if bot.channelPostHandler != nil {
+			return bot.channelPostHandler(ctx, getMessageText(msg))
+		}
+	}
+	if msg := update.EditedChannelPost; msg != nil {
+		if msg.IsCommand() && bot.channelPostCommands && bot.editedCommands {
+			bot.handleCommand(ctx, msg)
+		} else if bot.editedChannelPostHandler != nil {
+			return bot.editedChannelPostHandler(ctx, getMessageText(msg))
+		}
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		bot.handleCallback(ctx, update.CallbackQuery)
+	}
+	if update.MyChatMember != nil && bot.myChatMemberHandler != nil {
+		return bot.myChatMemberHandler(ctx, newChatMemberUpdate(update.MyChatMember))
+	}
+	if update.ChatMember != nil && bot.chatMemberHandler != nil {
+		return bot.chatMemberHandler(ctx, newChatMemberUpdate(update.ChatMember))
+	}
+	if update.ShippingQuery != nil {
+		return bot.handleShippingQuery(ctx, update.ShippingQuery)
+	}
+	if update.PreCheckoutQuery != nil {
+		return bot.handlePreCheckoutQuery(ctx, update.PreCheckoutQuery)
+	}
+	if update.PollAnswer != nil {
+		return bot.handlePollAnswer(ctx, update.PollAnswer)
+	}
+	if q := update.InlineQuery; q != nil && bot.inlineQueryHandler != nil {
+		bot.handleInlineQuery(newInlineContext(ctx, bot, q), q)
+	}
+	if r := update.ChosenInlineResult; r != nil && bot.chosenInlineResultHandler != nil {
+		return bot.chosenInlineResultHandler(newChosenInlineContext(ctx, bot, r), r.ResultID, r.Query)
+	}
+	return nil
+}
+
+func (bot *Bot) newUpdateContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if bot.updateTimeout > 0 {
+		return context.WithTimeout(parent, bot.updateTimeout)
+	}
+	return context.WithCancel(parent)
+}
+
+func (bot *Bot) commitUpdate(updateID int) {
+	if offset, ok := bot.offsets.done(updateID); ok {
+		bot.saveOffset(offset)
+	}
+}
+
+func (bot *Bot) loadOffset() int {
+	value, err := bot.cache.Get("offset")
+	if err != nil {
+		if err != razcache.ErrNotFound {
+			bot.logger.Error("failed to load update offset", slog.Any("err", err))
+		}
+		return 0
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil {
+		bot.logger.Error("invalid stored update offset", slog.String("offset", value))
+		return 0
+	}
+	return offset
+}
+
+func (bot *Bot) saveOffset(offset int) {
+	// telegram may restart update IDs from a random number after a week of inactivity
+	if err := bot.cache.Set("offset", strconv.Itoa(offset), time.Hour*24*7); err != nil {
+		bot.logger.Error("failed to save update offset", slog.Int("offset", offset), slog.Any("err", err))
+	}
+}
+
 func (bot *Bot) getChatCache(chatID int64) (razcache.Cache, error) {
 	return bot.cache.SubCache(fmt.Sprintf("chatdata:%d:", chatID)), nil
 }
@@ -108,100 +312,209 @@ func (bot *Bot) getUsernameFromUserID(userID int64) (string, error) {
 	return fmt.Sprintf("user:%d", userID), nil
 }
 
-func (bot *Bot) send(c tgbotapi.Chattable) (int, bool) {
-	resp, err := bot.api.Send(c)
+func (bot *Bot) send(ctx context.Context, chatID int64, c tgbotapi.Chattable) (int, bool) {
+	msg, err := bot.sendChattable(ctx, chatID, c, true)
 	if err != nil {
 		bot.logger.Error("failed to send message", slog.Any("err", err))
 		return 0, false
 	}
-	return resp.MessageID, true
+	return msg.MessageID, true
 }
 
-func (bot *Bot) sendDialogMessage(dlg *Dialog, msg dialogMessage) {
+func (bot *Bot) sendChattable(ctx context.Context, chatID int64, c tgbotapi.Chattable, retryable bool) (Message, error) {
+	var resp tgbotapi.Message
+	err := bot.request(ctx, chatID, retryable, func() (err error) {
+		resp, err = bot.api.Send(c)
+		return
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	return newSentMessage(bot, &resp), nil
+}
+
+func (bot *Bot) sendDialogMessage(ctx context.Context, dlg *Dialog, msg dialogMessage) {
 	c := msg.toChattable(dlg)
-	msgID, ok := bot.send(c)
+	if inv, ok := c.(tgbotapi.InvoiceConfig); ok && len(inv.ProviderToken) == 0 {
+		inv.ProviderToken = bot.paymentProviderToken
+		c = inv
+	}
+	msgID, ok := bot.send(ctx, dlg.chatID, c)
 	if ok {
 		msg.setMessageID(msgID)
 	}
 }
 
-func (bot *Bot) sendMessage(chatID int64, text string, replyID int) error {
+func (bot *Bot) sendMessage(ctx context.Context, chatID int64, text string, replyID int) (Message, error) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ReplyToMessageID = replyID
-	_, err := bot.api.Send(msg)
-	return err
+	msg.ParseMode = string(bot.parseMode)
+	return bot.sendChattable(ctx, chatID, msg, true)
 }
 
-func (bot *Bot) sendMedia(chatID int64, replyID int, media ...Media) error {
+// the default parse mode of the bot only applies to SendMessage and SendReply, which escape their arguments
+func (bot *Bot) sprintf(f string, args ...any) string {
+	return format.Sprintf(bot.parseMode, f, args...)
+}
+
+// sendMedia sends media in groups where telegram allows it and one by one otherwise
+func (bot *Bot) sendMedia(ctx context.Context, chatID int64, replyID int, media ...Media) ([]Message, error) {
 	if len(media) == 0 {
-		return nil
+		return nil, nil
+	}
+
+	if bot.fileIDCache {
+		if err := bot.resolveCachedFiles(ctx, media); err != nil {
+			return nil, err
+		}
+	}
+
+	var msgs []Message
+	var err error
+	if canGroupMedia(media) {
+		for i := 0; i < len(media) && err == nil; i += maxMediaGroupSize {
+			group := media[i:min(i+maxMediaGroupSize, len(media))]
+			var groupMsgs []Message
+			groupMsgs, err = bot.withUploadFallback(group, func() ([]Message, error) {
+				return bot.sendMediaGroup(ctx, chatID, replyID, group)
+			})
+			msgs = append(msgs, groupMsgs...)
+		}
+	} else {
+		for i := 0; i < len(media) && err == nil; i++ {
+			var singleMsgs []Message
+			singleMsgs, err = bot.withUploadFallback(media[i:i+1], func() ([]Message, error) {
+				msg, err := bot.sendSingleMedia(ctx, chatID, replyID, media[i])
+				if err != nil {
+					return nil, err
+				}
+				return []Message{msg}, nil
+			})
+			msgs = append(msgs, singleMsgs...)
+		}
+	}
+
+	if bot.fileIDCache {
+		bot.cacheFileIDs(media, msgs)
+	}
+	return msgs, err
+}
+
+func (bot *Bot) sendSingleMedia(ctx context.Context, chatID int64, replyID int, media Media) (Message, error) {
+	params, files, err := getMediaParams(media)
+	if err != nil {
+		return Message{}, err
 	}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("reply_to_message_id", replyID)
 
+	var msg tgbotapi.Message
+	method := mediaMethods[media.mediaType()]
+	if err := bot.requestUpload(ctx, chatID, media.isReusable(), method, params, files, &msg); err != nil {
+		return Message{}, err
+	}
+	return newSentMessage(bot, &msg), nil
+}
+
+func (bot *Bot) sendMediaGroup(ctx context.Context, chatID int64, replyID int, media []Media) ([]Message, error) {
 	if len(media) == 1 {
-		msg := media[0].toChattable(chatID, replyID)
-		_, err := bot.api.Send(msg)
-		return err
+		msg, err := bot.sendSingleMedia(ctx, chatID, replyID, media[0])
+		if err != nil {
+			return nil, err
+		}
+		return []Message{msg}, nil
+	}
 
+	params, files, err := getMediaGroupParams(media)
+	if err != nil {
+		return nil, err
 	}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("reply_to_message_id", replyID)
 
-	files := make([]any, len(media))
-	for i, media := range media {
-		files[i] = media.toInputMedia()
+	retryable := true
+	for _, media := range media {
+		retryable = retryable && media.isReusable()
 	}
-	group := tgbotapi.NewMediaGroup(chatID, files)
-	group.ReplyToMessageID = replyID
-	_, err := bot.api.SendMediaGroup(group)
-	return err
+
+	var resp []tgbotapi.Message
+	if err := bot.requestUpload(ctx, chatID, retryable, "sendMediaGroup", params, files, &resp); err != nil {
+		return nil, err
+	}
+	msgs := make([]Message, len(resp))
+	for i := range resp {
+		msgs[i] = newSentMessage(bot, &resp[i])
+	}
+	return msgs, nil
+}
+
+func (bot *Bot) requestUpload(ctx context.Context, chatID int64, retryable bool, method string, params tgbotapi.Params, files []tgbotapi.RequestFile, result any) error {
+	return bot.request(ctx, chatID, retryable, func() error {
+		var resp *tgbotapi.APIResponse
+		var err error
+		if len(files) > 0 {
+			resp, err = bot.api.UploadFiles(method, params, files)
+		} else {
+			resp, err = bot.api.MakeRequest(method, params)
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, result)
+	})
 }
 
-func (bot *Bot) sendSticker(chatID int64, stickerSet string, num int, replyID int) error {
+func (bot *Bot) sendSticker(ctx context.Context, chatID int64, stickerSet string, num int, replyID int) (Message, error) {
+	if err := ctx.Err(); err != nil {
+		return Message{}, err
+	}
 	stickers, err := bot.api.GetStickerSet(tgbotapi.GetStickerSetConfig{Name: stickerSet})
 	if err != nil {
-		return err
+		return Message{}, err
 	}
 	stickerCount := len(stickers.Stickers)
 	if stickerCount == 0 {
-		return fmt.Errorf("no stickers in set %q", stickerSet)
+		return Message{}, fmt.Errorf("no stickers in set %q", stickerSet)
 	}
 	if num >= stickerCount {
-		return fmt.Errorf("sticker number %d out of range (0-%d)", num, stickerCount-1)
+		return Message{}, fmt.Errorf("sticker number %d out of range (0-%d)", num, stickerCount-1)
 	}
 	if num < 0 {
-		num = bot.rand.Intn(stickerCount)
+		num = rand.Intn(stickerCount)
 	}
-	sticker := tgbotapi.NewSticker(chatID, tgbotapi.FileID(stickers.Stickers[num].FileID))
-	sticker.ReplyToMessageID = replyID
-	_, err = bot.api.Send(sticker)
-	return err
+	return bot.sendSingleMedia(ctx, chatID, replyID, NewSticker(FileIDSource(stickers.Stickers[num].FileID)))
 }
 
-func (bot *Bot) uploadFile(chatID int64, name string, r io.Reader) error {
+func (bot *Bot) uploadFile(ctx context.Context, chatID int64, name string, r io.Reader) (Message, error) {
 	if rc, ok := r.(io.ReadCloser); ok {
 		// tgbotapi might or might not close the reader, so let's do it only here
-		r = &wrappedReader{Reader: r}
 		defer rc.Close()
 	}
-	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{
-		Name:   name,
-		Reader: r,
-	})
-	_, err := bot.api.Send(doc)
-	return err
+	msgs, err := bot.sendMedia(ctx, chatID, 0, NewDocument(FileSource(name, &contextReader{ctx: ctx, Reader: r})))
+	if err != nil {
+		return Message{}, err
+	}
+	return msgs[0], nil
 }
 
-func (bot *Bot) uploadFileFromURL(chatID int64, url string) error {
-	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileURL(url))
-	_, err := bot.api.Send(doc)
-	return err
+func (bot *Bot) uploadFileFromURL(ctx context.Context, chatID int64, url string) (Message, error) {
+	return bot.sendSingleMedia(ctx, chatID, 0, NewDocument(URLSource(url)))
 }
 
 func (bot *Bot) DownloadFile(fileID string) (io.ReadCloser, error) {
+	return bot.downloadFile(bot.ctx, fileID)
+}
+
+func (bot *Bot) downloadFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	file, err := bot.api.GetFile(tgbotapi.FileConfig{FileID: fileID})
 	if err != nil {
 		return nil, err
 	}
 	url := fmt.Sprintf(bot.fileEndpoint, bot.token, file.FilePath)
-	return newLazyDownloader(url), nil
+	return newLazyDownloader(ctx, url), nil
 }
 
 func (bot *Bot) startDialog(ctx *Context, name string) error {
@@ -226,7 +539,7 @@ func (bot *Bot) startDialog(ctx *Context, name string) error {
 	}
 	updates, isDone := dlg.runHandler(ctx)
 	for _, update := range updates {
-		bot.sendDialogMessage(dlg, update)
+		bot.sendDialogMessage(ctx, dlg, update)
 	}
 	if !isDone {
 		bot.saveDialog(dlg)
@@ -298,7 +611,7 @@ func (bot *Bot) handleDialogInput(ctx *Context, dlg *Dialog, kind dialogInputKin
 		bot.logger.Error("dialog error", slogDialog(dlg), slog.Any("err", err))
 	}
 	for _, update := range updates {
-		bot.sendDialogMessage(dlg, update)
+		bot.sendDialogMessage(ctx, dlg, update)
 	}
 	if isDone {
 		bot.deleteDialog(dlg)
@@ -308,29 +621,54 @@ func (bot *Bot) handleDialogInput(ctx *Context, dlg *Dialog, kind dialogInputKin
 	return true
 }
 
-func (bot *Bot) callCommand(cmd string, ctx *Context, args []string) error {
+func (bot *Bot) getCommand(cmd string) *command {
+	cmd = strings.ToLower(cmd)
 	if c, ok := bot.commands[cmd]; ok {
-		resps, err := c.Call(ctx, args)
-		handleCommandResponses(ctx, resps)
+		return c
+	}
+	return bot.commands[bot.commandAliases[cmd]]
+}
+
+func (bot *Bot) callCommand(cmd string, ctx *Context, argsText string) error {
+	// unknown commands might be meant for other bots, so they must not use up rate limit tokens
+	c := bot.getCommand(cmd)
+	if c == nil {
+		return &UnknownCommandError{Cmd: cmd}
+	}
+	// denied users shouldn't use up rate limit tokens either
+	if err := bot.checkPermissions(ctx, c); err != nil {
+		return err
+	}
+	if err := bot.checkRateLimits(ctx, c); err != nil {
+		return err
+	}
+	args, err := c.parseArgs(argsText)
+	if err != nil {
+		return err
+	}
+	if err := c.validateArgs(args); err != nil {
 		return err
 	}
-	return &commander.UnknownCommandError{Cmd: cmd}
+	resps, err := c.Call(ctx, args)
+	handleCommandResponses(ctx, resps)
+	return err
 }
 
-func (bot *Bot) handleCommand(msg *tgbotapi.Message) {
-	ctx := newContext(bot, msg)
-	cmd := msg.Command()
-	args := strings.Fields(msg.CommandArguments())
-	if err := bot.callCommand(cmd, ctx, args); err != nil {
-		reply := tgbotapi.NewMessage(msg.Chat.ID, err.Error())
-		if !msg.Chat.IsPrivate() {
-			reply.ReplyToMessageID = msg.MessageID
+func (bot *Bot) handleCommand(ctx *Context, msg *tgbotapi.Message) {
+	cmd, botName, isAddressed := strings.Cut(msg.CommandWithAt(), "@")
+	if isAddressed && !strings.EqualFold(botName, bot.api.Self.UserName) {
+		return
+	}
+	if err := bot.callCommand(cmd, ctx, msg.CommandArguments()); err != nil {
+		// unknown commands in groups are most likely meant for other bots
+		if _, ok := err.(*UnknownCommandError); ok && !ctx.isPrivate && !isAddressed {
+			return
 		}
-		bot.send(reply)
+		bot.handleCommandError(ctx, err)
 	}
 }
 
-func (bot *Bot) handleMessage(msg *tgbotapi.Message) {
+func (bot *Bot) handleMessage(ctx *Context, msg *tgbotapi.Message) error {
 	if dlg := bot.getDialog(msg.From.ID, msg.Chat.ID); dlg != nil {
 		if !dlg.isPrivate() {
 			q := dlg.LastQuery()
@@ -338,35 +676,38 @@ func (bot *Bot) handleMessage(msg *tgbotapi.Message) {
 				goto fallback
 			}
 		}
-		ctx := newContext(bot, msg)
 		if bot.handleDialogInput(ctx, dlg, dialogInputText, msg.Text) {
-			return
+			return nil
 		}
 	}
 fallback:
 	if bot.defaultMsgHandler != nil {
-		ctx := newContext(bot, msg)
-		err := bot.defaultMsgHandler(ctx, msg.Text)
-		if err != nil {
-			bot.logger.Error("default message handler error", slogMessage(msg), slog.Any("err", err))
+		if err := bot.defaultMsgHandler(ctx, msg.Text); err != nil {
+			return fmt.Errorf("default message handler: %v", err)
 		}
 	}
+	return nil
 }
 
-func (bot *Bot) handleCallback(q *tgbotapi.CallbackQuery) {
+func (bot *Bot) handleCallback(ctx *Context, q *tgbotapi.CallbackQuery) {
 	callback := tgbotapi.NewCallback(q.ID, "Input not handled")
 	if dlg := bot.getDialog(q.From.ID, q.Message.Chat.ID); dlg != nil {
-		ctx := newContext(bot, q.Message)
 		if bot.handleDialogInput(ctx, dlg, dialogInputCallback, q.Data) {
 			callback.Text = ""
 		}
 	}
+	if len(callback.Text) > 0 && bot.callbackQueryHandler != nil {
+		callback.Text = ""
+		if err := bot.callbackQueryHandler(ctx, q.Data); err != nil {
+			bot.logger.Error("callback query handler error", slogCallbackQuery(q), slog.Any("err", err))
+		}
+	}
 	if _, err := bot.api.Request(callback); err != nil {
 		bot.logger.Error("callback returned error", slogCallbackQuery(q), slog.Any("err", err))
 	}
 }
 
-func (bot *Bot) handleFile(msg *tgbotapi.Message, fileID string) {
+func (bot *Bot) handleFile(ctx *Context, msg *tgbotapi.Message, fileID string) {
 	if dlg := bot.getDialog(msg.From.ID, msg.Chat.ID); dlg != nil {
 		if !dlg.isPrivate() {
 			q := dlg.LastQuery()
@@ -374,7 +715,6 @@ func (bot *Bot) handleFile(msg *tgbotapi.Message, fileID string) {
 				return
 			}
 		}
-		ctx := newContext(bot, msg)
 		bot.handleDialogInput(ctx, dlg, dialogInputFile, fileID)
 	}
 }
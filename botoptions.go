@@ -7,30 +7,74 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/razzie/commander"
+	"github.com/razzie/botkit/format"
 )
 
 var defaultOptions = BotOptions{
-	apiEndpoint:  tgbotapi.APIEndpoint,
-	fileEndpoint: tgbotapi.FileEndpoint,
-	logger:       slog.Default(),
-	timeout:      30,
-	dialogTTL:    time.Hour * 24,
+	apiEndpoint:         tgbotapi.APIEndpoint,
+	fileEndpoint:        tgbotapi.FileEndpoint,
+	logger:              slog.Default(),
+	timeout:             30,
+	dialogTTL:           time.Hour * 24,
+	workers:             1,
+	shutdownTimeout:     time.Second * 10,
+	helpCommand:         true,
+	commandMenu:         true,
+	globalSendRateLimit: defaultGlobalSendRateLimit,
+	chatSendRateLimit:   defaultChatSendRateLimit,
+	groupSendRateLimit:  defaultGroupSendRateLimit,
+	sendRetries:         3,
+	chatRegistry:        true,
+	inlineCacheTime:     time.Minute * 5,
 }
 
 type BotOption func(*BotOptions)
 
 type BotOptions struct {
-	apiEndpoint       string
-	fileEndpoint      string
-	redisDSN          string
-	logger            *slog.Logger
-	offset            int
-	timeout           int
-	commands          map[string]*commander.Command
-	dialogs           map[string]DialogHandler
-	dialogTTL         time.Duration
-	defaultMsgHandler func(context.Context, string) error
+	apiEndpoint               string
+	fileEndpoint              string
+	redisDSN                  string
+	logger                    *slog.Logger
+	offset                    int
+	timeout                   int
+	commands                  map[string]*command
+	commandAliases            map[string]string
+	dialogs                   map[string]DialogHandler
+	dialogTTL                 time.Duration
+	defaultMsgHandler         func(context.Context, string) error
+	webhookSecret             string
+	workers                   int
+	middlewares               []Middleware
+	shutdownTimeout           time.Duration
+	updateTimeout             time.Duration
+	helpCommand               bool
+	commandMenu               bool
+	commandErrorHandler       CommandErrorHandler
+	commandErrorMessages      map[string]CommandErrorMessages
+	rateLimits                []RateLimit
+	globalSendRateLimit       RateLimit
+	chatSendRateLimit         RateLimit
+	groupSendRateLimit        RateLimit
+	sendRetries               int
+	chatRegistry              bool
+	chatMemberHandler         ChatMemberHandler
+	myChatMemberHandler       ChatMemberHandler
+	editedMsgHandler          MessageHandler
+	editedCommands            bool
+	channelPostHandler        MessageHandler
+	editedChannelPostHandler  MessageHandler
+	channelPostCommands       bool
+	inlineQueryHandler        InlineQueryHandler
+	chosenInlineResultHandler ChosenInlineResultHandler
+	inlineCacheTime           time.Duration
+	paymentProviderToken      string
+	shippingQueryHandler      ShippingQueryHandler
+	preCheckoutQueryHandler   PreCheckoutQueryHandler
+	successfulPaymentHandler  SuccessfulPaymentHandler
+	parseMode                 format.ParseMode
+	callbackQueryHandler      MessageHandler
+	fileIDCache               bool
+	pollAnswerHandler         PollAnswerHandler
 }
 
 func WithAPIEndpoint(apiEndpoint string) BotOption {
@@ -70,15 +114,26 @@ func WithTimeout(timeout int) BotOption {
 	}
 }
 
-func WithCommand(cmd string, callback any) BotOption {
+func WithCommand(cmd string, callback any, opts ...CommandOption) BotOption {
 	return func(bo *BotOptions) {
 		if bo.commands == nil {
-			bo.commands = make(map[string]*commander.Command)
+			bo.commands = make(map[string]*command)
 		}
-		if c, err := commander.NewCommand(callback, cmdContextResolver); err != nil {
-			bo.logger.Error("failed to create command %s: %v", cmd, err)
-		} else {
-			bo.commands[cmd] = c
+		c, err := newCommand(cmd, callback, opts...)
+		if err != nil {
+			bo.logger.Error("failed to create command", slog.String("cmd", cmd), slog.Any("err", err))
+			return
+		}
+		bo.commands[c.name] = c
+		for _, alias := range c.aliases {
+			if _, ok := bo.commands[alias]; ok {
+				bo.logger.Warn("command alias shadowed by command", slog.String("cmd", c.name), slog.String("alias", alias))
+				continue
+			}
+			if bo.commandAliases == nil {
+				bo.commandAliases = make(map[string]string)
+			}
+			bo.commandAliases[alias] = c.name
 		}
 	}
 }
@@ -103,3 +158,201 @@ func WithDefaultMessageHandler(h func(context.Context, string) error) BotOption
 		bo.defaultMsgHandler = h
 	}
 }
+
+func WithWebhookSecret(secret string) BotOption {
+	return func(bo *BotOptions) {
+		bo.webhookSecret = secret
+	}
+}
+
+func WithWorkers(workers int) BotOption {
+	return func(bo *BotOptions) {
+		bo.workers = workers
+	}
+}
+
+func WithMiddleware(middlewares ...Middleware) BotOption {
+	return func(bo *BotOptions) {
+		bo.middlewares = append(bo.middlewares, middlewares...)
+	}
+}
+
+func WithShutdownTimeout(timeout time.Duration) BotOption {
+	return func(bo *BotOptions) {
+		bo.shutdownTimeout = timeout
+	}
+}
+
+func WithUpdateTimeout(timeout time.Duration) BotOption {
+	return func(bo *BotOptions) {
+		bo.updateTimeout = timeout
+	}
+}
+
+func WithHelpCommand(enabled bool) BotOption {
+	return func(bo *BotOptions) {
+		bo.helpCommand = enabled
+	}
+}
+
+func WithCommandMenu(enabled bool) BotOption {
+	return func(bo *BotOptions) {
+		bo.commandMenu = enabled
+	}
+}
+
+func WithCommandErrorHandler(h CommandErrorHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.commandErrorHandler = h
+	}
+}
+
+func WithCommandErrorMessages(languageCode string, msgs CommandErrorMessages) BotOption {
+	return func(bo *BotOptions) {
+		if bo.commandErrorMessages == nil {
+			bo.commandErrorMessages = make(map[string]CommandErrorMessages)
+		}
+		bo.commandErrorMessages[languageCode] = msgs.withDefaults(defaultCommandErrorMessages)
+	}
+}
+
+func WithRateLimit(limits ...RateLimit) BotOption {
+	return func(bo *BotOptions) {
+		bo.rateLimits = append(bo.rateLimits, limits...)
+	}
+}
+
+func WithSendRateLimits(global, chat, group RateLimit) BotOption {
+	return func(bo *BotOptions) {
+		bo.globalSendRateLimit = global
+		bo.chatSendRateLimit = chat
+		bo.groupSendRateLimit = group
+	}
+}
+
+func WithSendRetries(retries int) BotOption {
+	return func(bo *BotOptions) {
+		bo.sendRetries = retries
+	}
+}
+
+func WithChatRegistry(enabled bool) BotOption {
+	return func(bo *BotOptions) {
+		bo.chatRegistry = enabled
+	}
+}
+
+func WithChatMemberHandler(h ChatMemberHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.chatMemberHandler = h
+	}
+}
+
+func WithMyChatMemberHandler(h ChatMemberHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.myChatMemberHandler = h
+	}
+}
+
+func WithEditedMessageHandler(h MessageHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.editedMsgHandler = h
+	}
+}
+
+func WithEditedCommands() BotOption {
+	return func(bo *BotOptions) {
+		bo.editedCommands = true
+	}
+}
+
+func WithChannelPostHandler(h MessageHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.channelPostHandler = h
+	}
+}
+
+func WithEditedChannelPostHandler(h MessageHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.editedChannelPostHandler = h
+	}
+}
+
+func WithChannelPostCommands() BotOption {
+	return func(bo *BotOptions) {
+		bo.channelPostCommands = true
+	}
+}
+
+func WithInlineQueryHandler(h InlineQueryHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.inlineQueryHandler = h
+	}
+}
+
+func WithChosenInlineResultHandler(h ChosenInlineResultHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.chosenInlineResultHandler = h
+	}
+}
+
+func WithInlineCacheTime(cacheTime time.Duration) BotOption {
+	return func(bo *BotOptions) {
+		bo.inlineCacheTime = cacheTime
+	}
+}
+
+func WithPaymentProviderToken(token string) BotOption {
+	return func(bo *BotOptions) {
+		bo.paymentProviderToken = token
+	}
+}
+
+func WithShippingQueryHandler(h ShippingQueryHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.shippingQueryHandler = h
+	}
+}
+
+// WithPreCheckoutQueryHandler sets a handler to validate orders. Without it every pre-checkout query is approved.
+func WithPreCheckoutQueryHandler(h PreCheckoutQueryHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.preCheckoutQueryHandler = h
+	}
+}
+
+func WithSuccessfulPaymentHandler(h SuccessfulPaymentHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.successfulPaymentHandler = h
+	}
+}
+
+// WithDefaultParseMode sets the parse mode of SendMessage and SendReply.
+// Their format string is treated as markup, while arguments are escaped unless they are format.Text values.
+func WithDefaultParseMode(mode format.ParseMode) BotOption {
+	return func(bo *BotOptions) {
+		bo.parseMode = mode
+	}
+}
+
+// WithCallbackQueryHandler sets a handler for the data of inline keyboard buttons that are not part of a dialog
+func WithCallbackQueryHandler(h MessageHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.callbackQueryHandler = h
+	}
+}
+
+// WithFileIDCache enables sending previously uploaded file IDs instead of files with identical content.
+// File sources are read into memory to hash their content.
+func WithFileIDCache(enabled bool) BotOption {
+	return func(bo *BotOptions) {
+		bo.fileIDCache = enabled
+	}
+}
+
+// WithPollAnswerHandler sets a handler for answers of non-anonymous polls sent by the bot
+func WithPollAnswerHandler(h PollAnswerHandler) BotOption {
+	return func(bo *BotOptions) {
+		bo.pollAnswerHandler = h
+	}
+}
@@ -0,0 +1,270 @@
+package botkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/razzie/razcache"
+)
+
+const broadcastStateTTL = time.Hour * 24 * 7
+
+var (
+	ErrBotBlocked      = errors.New("bot was blocked")
+	ErrBotKicked       = errors.New("bot was kicked")
+	ErrChatNotFound    = errors.New("chat not found")
+	ErrUserDeactivated = errors.New("user is deactivated")
+)
+
+type BroadcastFunc func(chat Chat) error
+
+type BroadcastOption func(*broadcastOptions)
+
+type broadcastOptions struct {
+	id          string
+	concurrency int
+	progress    func(BroadcastProgress)
+}
+
+type BroadcastProgress struct {
+	Total  int
+	Done   int
+	Sent   int
+	Failed int
+}
+
+type BroadcastResult struct {
+	Sent   int
+	Failed map[int64]error
+}
+
+// broadcastState is only saved as a whole once the broadcast is done,
+// until then the result of each chat is saved as soon as it's known
+type broadcastState struct {
+	Sent     int              `json:"sent"`
+	Failed   map[int64]string `json:"failed"`
+	IsDone   bool             `json:"is_done"`
+	finished map[int64]bool
+}
+
+type broadcastJob struct {
+	chatID int64
+	err    error
+}
+
+func WithBroadcastID(id string) BroadcastOption {
+	return func(bo *broadcastOptions) {
+		bo.id = id
+	}
+}
+
+func WithBroadcastConcurrency(concurrency int) BroadcastOption {
+	return func(bo *broadcastOptions) {
+		bo.concurrency = concurrency
+	}
+}
+
+func WithBroadcastProgress(progress func(BroadcastProgress)) BroadcastOption {
+	return func(bo *broadcastOptions) {
+		bo.progress = progress
+	}
+}
+
+// Broadcast calls send for every chat concurrently while respecting the send rate limits.
+// Broadcasts with an ID can be resumed after a restart by calling Broadcast again with the same ID and chat list.
+// Media from readers can only be sent once, so send should create its media for each chat.
+func (bot *Bot) Broadcast(chatIDs []int64, send BroadcastFunc, opts ...BroadcastOption) (*BroadcastResult, error) {
+	bo := broadcastOptions{concurrency: 8}
+	for _, opt := range opts {
+		opt(&bo)
+	}
+
+	state := bot.loadBroadcastState(bo.id, chatIDs)
+	if state.IsDone {
+		return state.toResult(), nil
+	}
+
+	var todo []int64
+	for _, chatID := range chatIDs {
+		if !state.finished[chatID] {
+			// duplicate chats are only sent to once
+			state.finished[chatID] = true
+			todo = append(todo, chatID)
+		}
+	}
+
+	jobs := make(chan broadcastJob)
+	results := make(chan broadcastJob)
+	for i := 0; i < max(bo.concurrency, 1); i++ {
+		go func() {
+			for job := range jobs {
+				job.err = send(newChat(bot, job.chatID))
+				results <- job
+			}
+		}()
+	}
+
+	pending := len(todo)
+	next := 0
+	progress := BroadcastProgress{
+		Total:  len(todo) + state.Sent + len(state.Failed),
+		Done:   state.Sent + len(state.Failed),
+		Sent:   state.Sent,
+		Failed: len(state.Failed),
+	}
+	var err error
+	done := bot.ctx.Done()
+	for inFlight := 0; pending > 0; {
+		var queue chan broadcastJob
+		var job broadcastJob
+		if next < len(todo) && err == nil {
+			queue = jobs
+			job = broadcastJob{chatID: todo[next]}
+		}
+
+		select {
+		case queue <- job:
+			next++
+			inFlight++
+		case result := <-results:
+			inFlight--
+			pending--
+			progress.Done++
+			bot.saveBroadcastResult(bo.id, result.chatID, result.err)
+			if result.err != nil {
+				state.Failed[result.chatID] = result.err.Error()
+				progress.Failed++
+				if err := classifyBroadcastError(result.err); errors.Is(err, ErrBotBlocked) || errors.Is(err, ErrBotKicked) {
+					bot.markChatKicked(result.chatID)
+				}
+			} else {
+				state.Sent++
+				progress.Sent++
+			}
+			if bo.progress != nil {
+				bo.progress(progress)
+			}
+		case <-done:
+			err = bot.ctx.Err()
+			done = nil
+		}
+
+		if err != nil && inFlight == 0 {
+			break
+		}
+	}
+	close(jobs)
+
+	if err == nil {
+		state.IsDone = true
+		bot.saveBroadcastState(bo.id, state)
+	}
+	return state.toResult(), err
+}
+
+func newBroadcastState() *broadcastState {
+	return &broadcastState{
+		Failed:   make(map[int64]string),
+		finished: make(map[int64]bool),
+	}
+}
+
+func (bot *Bot) loadBroadcastState(id string, chatIDs []int64) *broadcastState {
+	state := newBroadcastState()
+	if len(id) == 0 {
+		return state
+	}
+	stateJson, err := bot.cache.Get("broadcast:" + id)
+	if err == nil {
+		if err := json.Unmarshal([]byte(stateJson), state); err != nil {
+			bot.logger.Error("failed to unmarshal broadcast state", slog.String("id", id), slog.Any("err", err))
+		} else if state.IsDone {
+			return state
+		}
+		state = newBroadcastState()
+	} else if err != razcache.ErrNotFound {
+		bot.logger.Error("failed to load broadcast state", slog.String("id", id), slog.Any("err", err))
+	}
+
+	// chats are skipped only if their result was saved, so nothing gets sent twice
+	for _, chatID := range chatIDs {
+		if state.finished[chatID] {
+			continue
+		}
+		result, err := bot.cache.Get(fmt.Sprintf("broadcast:%s:%d", id, chatID))
+		if err != nil {
+			if err != razcache.ErrNotFound {
+				bot.logger.Error("failed to load broadcast result", slog.String("id", id), slog.Int64("chatID", chatID), slog.Any("err", err))
+			}
+			continue
+		}
+		state.finished[chatID] = true
+		if errMsg, failed := strings.CutPrefix(result, "failed:"); failed {
+			state.Failed[chatID] = errMsg
+		} else {
+			state.Sent++
+		}
+	}
+	return state
+}
+
+func (bot *Bot) saveBroadcastResult(id string, chatID int64, sendErr error) {
+	if len(id) == 0 {
+		return
+	}
+	result := "sent"
+	if sendErr != nil {
+		result = "failed:" + sendErr.Error()
+	}
+	if err := bot.cache.Set(fmt.Sprintf("broadcast:%s:%d", id, chatID), result, broadcastStateTTL); err != nil {
+		bot.logger.Error("failed to save broadcast result", slog.String("id", id), slog.Int64("chatID", chatID), slog.Any("err", err))
+	}
+}
+
+func (bot *Bot) saveBroadcastState(id string, state *broadcastState) {
+	if len(id) == 0 {
+		return
+	}
+	stateJson, err := json.Marshal(state)
+	if err != nil {
+		bot.logger.Error("failed to marshal broadcast state", slog.String("id", id), slog.Any("err", err))
+		return
+	}
+	if err := bot.cache.Set("broadcast:"+id, string(stateJson), broadcastStateTTL); err != nil {
+		bot.logger.Error("failed to save broadcast state", slog.String("id", id), slog.Any("err", err))
+	}
+}
+
+func (state *broadcastState) toResult() *BroadcastResult {
+	result := &BroadcastResult{
+		Sent:   state.Sent,
+		Failed: make(map[int64]error, len(state.Failed)),
+	}
+	for chatID, errMsg := range state.Failed {
+		result.Failed[chatID] = classifyBroadcastError(errors.New(errMsg))
+	}
+	return result
+}
+
+func classifyBroadcastError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "bot was blocked"):
+		return fmt.Errorf("%w: %s", ErrBotBlocked, err.Error())
+	case strings.Contains(msg, "bot was kicked"):
+		return fmt.Errorf("%w: %s", ErrBotKicked, err.Error())
+	case strings.Contains(msg, "chat not found"):
+		return fmt.Errorf("%w: %s", ErrChatNotFound, err.Error())
+	case strings.Contains(msg, "user is deactivated"):
+		return fmt.Errorf("%w: %s", ErrUserDeactivated, err.Error())
+	default:
+		return err
+	}
+}
@@ -1,7 +1,6 @@
 package botkit
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/razzie/razcache"
@@ -19,30 +18,102 @@ func newChat(bot *Bot, chatID int64) Chat {
 	}
 }
 
-func (chat Chat) SendMessage(format string, args ...any) error {
-	return chat.bot.sendMessage(chat.chatID, fmt.Sprintf(format, args...), 0)
+func (chat Chat) SendMessage(format string, args ...any) (Message, error) {
+	return chat.bot.sendMessage(chat.bot.ctx, chat.chatID, chat.bot.sprintf(format, args...), 0)
 }
 
-func (chat Chat) SendMedia(media ...Media) error {
-	return chat.bot.sendMedia(chat.chatID, 0, media...)
+func (chat Chat) Send(text string, opts ...SendOption) (Message, error) {
+	return chat.bot.sendText(chat.bot.ctx, chat.chatID, text, 0, opts)
 }
 
-func (chat Chat) SendSticker(stickerSet string, num int) error {
-	return chat.bot.sendSticker(chat.chatID, stickerSet, num, 0)
+func (chat Chat) EditMessage(msg Message, text string, opts ...SendOption) (Message, error) {
+	return chat.bot.editMessage(chat.bot.ctx, msg, text, opts)
 }
 
-func (chat Chat) UploadFile(name string, r io.Reader) error {
-	return chat.bot.uploadFile(chat.chatID, name, r)
+func (chat Chat) EditCaption(msg Message, caption string, opts ...SendOption) (Message, error) {
+	return chat.bot.editCaption(chat.bot.ctx, msg, caption, opts)
 }
 
-func (chat Chat) UploadFileFromURL(url string) error {
-	return chat.bot.uploadFileFromURL(chat.chatID, url)
+func (chat Chat) EditReplyMarkup(msg Message, kb Keyboard) (Message, error) {
+	return chat.bot.editReplyMarkup(chat.bot.ctx, msg, kb)
+}
+
+func (chat Chat) DeleteMessage(msg Message) error {
+	return chat.bot.deleteMessage(chat.bot.ctx, msg)
+}
+
+func (chat Chat) PinMessage(msg Message, opts ...SendOption) error {
+	return chat.bot.pinMessage(chat.bot.ctx, msg, opts)
+}
+
+func (chat Chat) UnpinMessage(msg Message) error {
+	return chat.bot.unpinMessage(chat.bot.ctx, msg)
+}
+
+func (chat Chat) ForwardMessage(msg Message, opts ...SendOption) (Message, error) {
+	return chat.bot.forwardMessage(chat.bot.ctx, chat.chatID, msg, opts)
+}
+
+func (chat Chat) CopyMessage(msg Message, opts ...SendOption) (Message, error) {
+	return chat.bot.copyMessage(chat.bot.ctx, chat.chatID, msg, opts)
+}
+
+func (chat Chat) SendMedia(media ...Media) ([]Message, error) {
+	return chat.bot.sendMedia(chat.bot.ctx, chat.chatID, 0, media...)
+}
+
+func (chat Chat) SendSticker(stickerSet string, num int) (Message, error) {
+	return chat.bot.sendSticker(chat.bot.ctx, chat.chatID, stickerSet, num, 0)
+}
+
+func (chat Chat) SendInvoice(inv *Invoice) (Message, error) {
+	return chat.bot.sendInvoice(chat.bot.ctx, chat.chatID, inv, 0)
+}
+
+func (chat Chat) SendLocation(loc *Location, opts ...SendOption) (Message, error) {
+	return chat.bot.sendContent(chat.bot.ctx, chat.chatID, loc, 0, opts)
+}
+
+func (chat Chat) EditLiveLocation(msg Message, latitude, longitude float64) (Message, error) {
+	return chat.bot.editLiveLocation(chat.bot.ctx, msg, latitude, longitude)
+}
+
+func (chat Chat) StopLiveLocation(msg Message) (Message, error) {
+	return chat.bot.stopLiveLocation(chat.bot.ctx, msg)
+}
+
+func (chat Chat) SendVenue(venue *Venue, opts ...SendOption) (Message, error) {
+	return chat.bot.sendContent(chat.bot.ctx, chat.chatID, venue, 0, opts)
+}
+
+func (chat Chat) SendContact(contact *Contact, opts ...SendOption) (Message, error) {
+	return chat.bot.sendContent(chat.bot.ctx, chat.chatID, contact, 0, opts)
+}
+
+func (chat Chat) SendPoll(poll *Poll, opts ...SendOption) (Message, error) {
+	return chat.bot.sendContent(chat.bot.ctx, chat.chatID, poll, 0, opts)
+}
+
+func (chat Chat) SendDice(emoji string, opts ...SendOption) (Message, error) {
+	return chat.bot.sendContent(chat.bot.ctx, chat.chatID, dice(emoji), 0, opts)
+}
+
+func (chat Chat) UploadFile(name string, r io.Reader) (Message, error) {
+	return chat.bot.uploadFile(chat.bot.ctx, chat.chatID, name, r)
+}
+
+func (chat Chat) UploadFileFromURL(url string) (Message, error) {
+	return chat.bot.uploadFileFromURL(chat.bot.ctx, chat.chatID, url)
 }
 
 func (chat Chat) GetCache() (razcache.Cache, error) {
 	return chat.bot.getChatCache(chat.chatID)
 }
 
+func (chat Chat) GetMessage(messageID int) Message {
+	return newMessage(chat.bot, chat.chatID, messageID)
+}
+
 func (chat Chat) GetID() int64 {
 	return chat.chatID
 }
@@ -0,0 +1,75 @@
+package botkit
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var allUpdateTypes = []string{
+	"message",
+	"edited_message",
+	"channel_post",
+	"edited_channel_post",
+	"inline_query",
+	"chosen_inline_result",
+	"callback_query",
+	"shipping_query",
+	"pre_checkout_query",
+	"poll",
+	"poll_answer",
+	"my_chat_member",
+	"chat_member",
+}
+
+type ChatMemberHandler func(ctx *Context, update ChatMemberUpdate) error
+
+type ChatMemberUpdate struct {
+	UserID    int64
+	Username  string
+	ByUserID  int64
+	OldStatus string
+	NewStatus string
+	IsBot     bool
+}
+
+func newChatMemberUpdate(u *tgbotapi.ChatMemberUpdated) ChatMemberUpdate {
+	update := ChatMemberUpdate{
+		ByUserID:  u.From.ID,
+		OldStatus: u.OldChatMember.Status,
+		NewStatus: u.NewChatMember.Status,
+	}
+	if user := u.NewChatMember.User; user != nil {
+		update.UserID = user.ID
+		update.Username = user.UserName
+		update.IsBot = user.IsBot
+	}
+	return update
+}
+
+func (u ChatMemberUpdate) IsJoined() bool {
+	return !isMemberStatus(u.OldStatus) && isMemberStatus(u.NewStatus)
+}
+
+func (u ChatMemberUpdate) IsLeft() bool {
+	return isMemberStatus(u.OldStatus) && !isMemberStatus(u.NewStatus)
+}
+
+func (u ChatMemberUpdate) IsKicked() bool {
+	return u.NewStatus == "kicked"
+}
+
+func isMemberStatus(status string) bool {
+	switch status {
+	case "creator", "administrator", "member", "restricted":
+		return true
+	default:
+		return false
+	}
+}
+
+// chat_member updates are only sent by telegram if explicitly requested
+func (bot *Bot) getAllowedUpdates() []string {
+	if bot.chatMemberHandler != nil {
+		return allUpdateTypes
+	}
+	return nil
+}
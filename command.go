@@ -2,16 +2,66 @@ package botkit
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/razzie/commander"
 )
 
 var cmdContextResolver = commander.ContextResolverFunc(resolveContext)
 
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 type CommandResponse func(*Context) error
 
+type command struct {
+	*commander.Command
+	name         string
+	aliases      []string
+	description  string
+	descriptions map[string]string
+	usage        string
+	scopes       []CommandScope
+	hidden       bool
+	permissions  commandPermissions
+	rateLimits   []RateLimit
+	params       []reflect.Type
+	variadic     bool
+	rawTail      bool
+}
+
+func newCommand(name string, callback any, opts ...CommandOption) (*command, error) {
+	cmd, err := commander.NewCommand(callback, cmdContextResolver)
+	if err != nil {
+		return nil, err
+	}
+	c := &command{
+		Command: cmd,
+		name:    strings.ToLower(name),
+	}
+	c.params, c.variadic = getCommandParams(callback)
+	for _, opt := range opts {
+		opt(c)
+	}
+	for i, alias := range c.aliases {
+		c.aliases[i] = strings.ToLower(alias)
+	}
+	if c.rawTail && (c.variadic || len(c.params) == 0 || c.params[len(c.params)-1].Kind() != reflect.String) {
+		return nil, fmt.Errorf("raw trailing argument requires a string as last parameter")
+	}
+	if len(c.usage) == 0 {
+		c.usage = c.getDefaultUsage()
+	}
+	if len(c.scopes) == 0 {
+		c.scopes = c.getDefaultScopes()
+	}
+	return c, nil
+}
+
 func StartDialog(name string) CommandResponse {
 	return func(ctx *Context) error {
 		return ctx.StartDialog(name)
@@ -20,52 +70,205 @@ func StartDialog(name string) CommandResponse {
 
 func SendMessage(format string, args ...any) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.SendMessage(format, args...)
+		_, err := ctx.SendMessage(format, args...)
+		return err
 	}
 }
 
 func SendReply(format string, args ...any) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.SendReply(format, args...)
+		_, err := ctx.SendReply(format, args...)
+		return err
+	}
+}
+
+func Send(text string, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.Send(text, opts...)
+		return err
+	}
+}
+
+func Reply(text string, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.Reply(text, opts...)
+		return err
 	}
 }
 
 func SendMedia(media ...Media) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.SendMedia(media...)
+		_, err := ctx.SendMedia(media...)
+		return err
 	}
 }
 
 func ReplyMedia(media ...Media) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.ReplyMedia(media...)
+		_, err := ctx.ReplyMedia(media...)
+		return err
 	}
 }
 
 func SendSticker(stickerSet string, num int) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.SendSticker(stickerSet, num)
+		_, err := ctx.SendSticker(stickerSet, num)
+		return err
 	}
 }
 
 func ReplySticker(stickerSet string, num int) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.ReplySticker(stickerSet, num)
+		_, err := ctx.ReplySticker(stickerSet, num)
+		return err
+	}
+}
+
+func SendLocation(loc *Location, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.SendLocation(loc, opts...)
+		return err
+	}
+}
+
+func SendVenue(venue *Venue, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.SendVenue(venue, opts...)
+		return err
+	}
+}
+
+func SendContact(contact *Contact, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.SendContact(contact, opts...)
+		return err
+	}
+}
+
+func SendPoll(poll *Poll, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.SendPoll(poll, opts...)
+		return err
+	}
+}
+
+func SendDice(emoji string, opts ...SendOption) CommandResponse {
+	return func(ctx *Context) error {
+		_, err := ctx.SendDice(emoji, opts...)
+		return err
 	}
 }
 
 func UploadFile(name string, r io.Reader) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.UploadFile(name, r)
+		_, err := ctx.UploadFile(name, r)
+		return err
 	}
 }
 
 func UploadFileFromURL(url string) CommandResponse {
 	return func(ctx *Context) error {
-		return ctx.UploadFileFromURL(url)
+		_, err := ctx.UploadFileFromURL(url)
+		return err
 	}
 }
 
+func (c *command) getDefaultScopes() []CommandScope {
+	switch {
+	case c.permissions.privateOnly:
+		return []CommandScope{PrivateCommandScope}
+	case c.permissions.adminOnly:
+		return []CommandScope{PrivateCommandScope, AdminCommandScope}
+	case c.permissions.groupOnly:
+		return []CommandScope{GroupCommandScope}
+	default:
+		return []CommandScope{DefaultCommandScope}
+	}
+}
+
+func (c *command) getDescription(languageCode string) string {
+	if desc, ok := c.descriptions[languageCode]; ok {
+		return desc
+	}
+	return c.description
+}
+
+func (c *command) getDefaultUsage() string {
+	usage := "/" + c.name
+	for i, param := range c.params {
+		if c.variadic && i == len(c.params)-1 {
+			usage += " [" + getParamTypeName(param.Elem()) + "...]"
+		} else {
+			usage += " <" + getParamTypeName(param) + ">"
+		}
+	}
+	return usage
+}
+
+func (c *command) parseArgs(text string) ([]string, error) {
+	n := -1
+	if c.rawTail {
+		n = len(c.params) - 1
+	}
+	args, rest, err := splitArgs(text, n)
+	if err != nil {
+		return nil, &ArgSyntaxError{
+			Cmd:   c.name,
+			Usage: c.usage,
+			Err:   err,
+		}
+	}
+	if len(rest) > 0 {
+		args = append(args, rest)
+	}
+	return args, nil
+}
+
+func (c *command) validateArgs(args []string) error {
+	fixed := len(c.params)
+	if c.variadic {
+		fixed--
+	}
+	if len(args) < fixed || (!c.variadic && len(args) > fixed) {
+		return &ArgCountError{
+			Cmd:      c.name,
+			Usage:    c.usage,
+			Got:      len(args),
+			Min:      fixed,
+			Variadic: c.variadic,
+		}
+	}
+	for i, arg := range args {
+		var param reflect.Type
+		if i < fixed {
+			param = c.params[i]
+		} else {
+			param = c.params[len(c.params)-1].Elem()
+		}
+		if !isValidParamValue(param, arg) {
+			return &ArgTypeError{
+				Cmd:   c.name,
+				Usage: c.usage,
+				Pos:   i + 1,
+				Arg:   arg,
+				Type:  getParamTypeName(param),
+			}
+		}
+	}
+	return nil
+}
+
+func (c *command) isInScope(scopes ...CommandScope) bool {
+	for _, scope := range c.scopes {
+		for _, s := range scopes {
+			if scope == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (cresp CommandResponse) handle(ctx *Context) {
 	if cresp == nil {
 		return
@@ -91,3 +294,47 @@ func handleCommandResponses(ctx *Context, resps []any) {
 func resolveContext(ctx context.Context) (*Context, error) {
 	return ctx.(*Context), nil
 }
+
+func getCommandParams(callback any) (params []reflect.Type, variadic bool) {
+	t := reflect.TypeOf(callback)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil, false
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		if in := t.In(i); !in.Implements(contextType) {
+			params = append(params, in)
+		}
+	}
+	return params, t.IsVariadic()
+}
+
+func getParamTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "true/false"
+	case reflect.String:
+		return "text"
+	default:
+		return strings.ToLower(t.Name())
+	}
+}
+
+func isValidParamValue(t reflect.Type, value string) bool {
+	var err error
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(value, t.Bits())
+	case reflect.Bool:
+		_, err = strconv.ParseBool(value)
+	}
+	return err == nil
+}
@@ -0,0 +1,63 @@
+package botkit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func newTestCommand(name string, callback any) *command {
+	c := &command{name: name}
+	c.params, c.variadic = getCommandParams(callback)
+	c.usage = c.getDefaultUsage()
+	return c
+}
+
+func TestCommandDefaultUsage(t *testing.T) {
+	tests := []struct {
+		callback any
+		usage    string
+	}{
+		{func(context.Context) {}, "/cmd"},
+		{func(context.Context, int, string) {}, "/cmd <integer> <text>"},
+		{func(uint8, float64, bool) {}, "/cmd <integer> <number> <true/false>"},
+		{func(context.Context, string, ...int) {}, "/cmd <text> [integer...]"},
+		{func(...string) {}, "/cmd [text...]"},
+	}
+	for _, tt := range tests {
+		if usage := newTestCommand("cmd", tt.callback).usage; usage != tt.usage {
+			t.Errorf("usage = %q, want %q", usage, tt.usage)
+		}
+	}
+}
+
+func TestCommandValidateArgs(t *testing.T) {
+	fixed := newTestCommand("sum", func(context.Context, int, int) {})
+	variadic := newTestCommand("max", func(context.Context, string, ...float64) {})
+	small := newTestCommand("small", func(int8, uint8, bool) {})
+
+	tests := []struct {
+		cmd  *command
+		args []string
+		err  error
+	}{
+		{cmd: fixed, args: []string{"1", "-2"}},
+		{cmd: fixed, args: []string{"1"}, err: &ArgCountError{Cmd: "sum", Usage: "/sum <integer> <integer>", Got: 1, Min: 2}},
+		{cmd: fixed, args: []string{"1", "2", "3"}, err: &ArgCountError{Cmd: "sum", Usage: "/sum <integer> <integer>", Got: 3, Min: 2}},
+		{cmd: fixed, args: []string{"1", "x"}, err: &ArgTypeError{Cmd: "sum", Usage: "/sum <integer> <integer>", Pos: 2, Arg: "x", Type: "integer"}},
+		{cmd: variadic, args: []string{"a"}},
+		{cmd: variadic, args: []string{"a", "1.5", "2"}},
+		{cmd: variadic, args: nil, err: &ArgCountError{Cmd: "max", Usage: "/max <text> [number...]", Got: 0, Min: 1, Variadic: true}},
+		{cmd: variadic, args: []string{"a", "1", "b"}, err: &ArgTypeError{Cmd: "max", Usage: "/max <text> [number...]", Pos: 3, Arg: "b", Type: "number"}},
+		{cmd: small, args: []string{"-128", "255", "true"}},
+		{cmd: small, args: []string{"128", "0", "false"}, err: &ArgTypeError{Cmd: "small", Usage: "/small <integer> <integer> <true/false>", Pos: 1, Arg: "128", Type: "integer"}},
+		{cmd: small, args: []string{"0", "-1", "false"}, err: &ArgTypeError{Cmd: "small", Usage: "/small <integer> <integer> <true/false>", Pos: 2, Arg: "-1", Type: "integer"}},
+		{cmd: small, args: []string{"0", "0", "yes"}, err: &ArgTypeError{Cmd: "small", Usage: "/small <integer> <integer> <true/false>", Pos: 3, Arg: "yes", Type: "true/false"}},
+	}
+	for _, tt := range tests {
+		err := tt.cmd.validateArgs(tt.args)
+		if !reflect.DeepEqual(err, tt.err) {
+			t.Errorf("%s %q: error = %v, want %v", tt.cmd.name, tt.args, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package botkit
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var defaultCommandErrorMessages = CommandErrorMessages{
+	UnknownCommand:   "Unknown command: /%s",
+	WrongArgCount:    "Wrong number of arguments",
+	WrongArgType:     "Argument %d should be %s, got %q",
+	InvalidSyntax:    "Invalid arguments: %v",
+	PermissionDenied: "You are not allowed to use /%s here",
+	RateLimited:      "Slow down! Try again in %v",
+	Usage:            "Usage: %s",
+}
+
+type CommandErrorHandler func(ctx *Context, err error)
+
+// CommandErrorMessages contains the format strings of command error replies:
+// UnknownCommand gets the command name, WrongArgType gets the argument position, expected type and argument,
+// InvalidSyntax gets the parsing error, PermissionDenied gets the command name,
+// RateLimited gets the cooldown duration, Usage gets the usage string of the command
+type CommandErrorMessages struct {
+	UnknownCommand   string
+	WrongArgCount    string
+	WrongArgType     string
+	InvalidSyntax    string
+	PermissionDenied string
+	RateLimited      string
+	Usage            string
+}
+
+type UnknownCommandError struct {
+	Cmd string
+}
+
+type ArgCountError struct {
+	Cmd      string
+	Usage    string
+	Got      int
+	Min      int
+	Variadic bool
+}
+
+type ArgTypeError struct {
+	Cmd   string
+	Usage string
+	Pos   int
+	Arg   string
+	Type  string
+}
+
+type ArgSyntaxError struct {
+	Cmd   string
+	Usage string
+	Err   error
+}
+
+func (err *UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command: %s", err.Cmd)
+}
+
+func (err *ArgCountError) Error() string {
+	if err.Variadic {
+		return fmt.Sprintf("%s: expected at least %d arguments, got %d", err.Cmd, err.Min, err.Got)
+	}
+	return fmt.Sprintf("%s: expected %d arguments, got %d", err.Cmd, err.Min, err.Got)
+}
+
+func (err *ArgTypeError) Error() string {
+	return fmt.Sprintf("%s: argument %d should be %s, got %q", err.Cmd, err.Pos, err.Type, err.Arg)
+}
+
+func (err *ArgSyntaxError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Cmd, err.Err)
+}
+
+func (err *ArgSyntaxError) Unwrap() error {
+	return err.Err
+}
+
+func DefaultCommandErrorHandler(ctx *Context, err error) {
+	msgs := ctx.bot.getCommandErrorMessages(ctx.languageCode)
+	var lines []string
+	switch err := err.(type) {
+	case *UnknownCommandError:
+		lines = append(lines, fmt.Sprintf(msgs.UnknownCommand, err.Cmd))
+	case *ArgCountError:
+		lines = append(lines, msgs.WrongArgCount, fmt.Sprintf(msgs.Usage, err.Usage))
+	case *ArgTypeError:
+		lines = append(lines, fmt.Sprintf(msgs.WrongArgType, err.Pos, err.Type, err.Arg), fmt.Sprintf(msgs.Usage, err.Usage))
+	case *ArgSyntaxError:
+		lines = append(lines, fmt.Sprintf(msgs.InvalidSyntax, err.Err), fmt.Sprintf(msgs.Usage, err.Usage))
+	case *PermissionError:
+		lines = append(lines, fmt.Sprintf(msgs.PermissionDenied, err.Cmd))
+	case *RateLimitError:
+		lines = append(lines, fmt.Sprintf(msgs.RateLimited, err.RetryAfter))
+	default:
+		lines = append(lines, err.Error())
+	}
+	reply := tgbotapi.NewMessage(ctx.chatID, strings.Join(lines, "\n"))
+	if !ctx.isPrivate {
+		reply.ReplyToMessageID = ctx.replyID
+	}
+	ctx.bot.send(ctx, ctx.chatID, reply)
+}
+
+func (bot *Bot) handleCommandError(ctx *Context, err error) {
+	bot.logger.Debug("command failed", slogContext(ctx), slog.Any("err", err))
+	if err, ok := err.(*RateLimitError); ok && err.repeated {
+		return
+	}
+	if bot.commandErrorHandler != nil {
+		bot.commandErrorHandler(ctx, err)
+	} else {
+		DefaultCommandErrorHandler(ctx, err)
+	}
+}
+
+// withDefaults fills the messages left empty, so partial translations don't end up as broken format strings
+func (msgs CommandErrorMessages) withDefaults(defaults CommandErrorMessages) CommandErrorMessages {
+	v := reflect.ValueOf(&msgs).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).Len() == 0 {
+			v.Field(i).Set(reflect.ValueOf(defaults).Field(i))
+		}
+	}
+	return msgs
+}
+
+func (bot *Bot) getCommandErrorMessages(languageCode string) CommandErrorMessages {
+	if msgs, ok := bot.commandErrorMessages[languageCode]; ok {
+		return msgs
+	}
+	if msgs, ok := bot.commandErrorMessages[""]; ok {
+		return msgs
+	}
+	return defaultCommandErrorMessages
+}
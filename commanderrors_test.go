@@ -0,0 +1,14 @@
+package botkit
+
+import (
+	"testing"
+)
+
+func TestCommandErrorMessagesWithDefaults(t *testing.T) {
+	msgs := CommandErrorMessages{UnknownCommand: "Ismeretlen parancs: /%s"}.withDefaults(defaultCommandErrorMessages)
+	want := defaultCommandErrorMessages
+	want.UnknownCommand = "Ismeretlen parancs: /%s"
+	if msgs != want {
+		t.Errorf("withDefaults = %+v, want %+v", msgs, want)
+	}
+}
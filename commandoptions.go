@@ -0,0 +1,88 @@
+package botkit
+
+const (
+	DefaultCommandScope CommandScope = "default"
+	PrivateCommandScope CommandScope = "all_private_chats"
+	GroupCommandScope   CommandScope = "all_group_chats"
+	AdminCommandScope   CommandScope = "all_chat_administrators"
+)
+
+type CommandScope string
+
+type CommandOption func(*command)
+
+func Description(description string) CommandOption {
+	return func(c *command) {
+		c.description = description
+	}
+}
+
+func LocalizedDescription(languageCode, description string) CommandOption {
+	return func(c *command) {
+		if c.descriptions == nil {
+			c.descriptions = make(map[string]string)
+		}
+		c.descriptions[languageCode] = description
+	}
+}
+
+func Usage(usage string) CommandOption {
+	return func(c *command) {
+		c.usage = usage
+	}
+}
+
+func InScopes(scopes ...CommandScope) CommandOption {
+	return func(c *command) {
+		c.scopes = scopes
+	}
+}
+
+func Aliases(aliases ...string) CommandOption {
+	return func(c *command) {
+		c.aliases = append(c.aliases, aliases...)
+	}
+}
+
+func AdminOnly() CommandOption {
+	return func(c *command) {
+		c.permissions.adminOnly = true
+	}
+}
+
+func PrivateOnly() CommandOption {
+	return func(c *command) {
+		c.permissions.privateOnly = true
+	}
+}
+
+func GroupOnly() CommandOption {
+	return func(c *command) {
+		c.permissions.groupOnly = true
+	}
+}
+
+func AllowUsers(userIDs ...int64) CommandOption {
+	return func(c *command) {
+		c.permissions.allowedUsers = append(c.permissions.allowedUsers, userIDs...)
+	}
+}
+
+func RateLimited(limits ...RateLimit) CommandOption {
+	return func(c *command) {
+		c.rateLimits = append(c.rateLimits, limits...)
+	}
+}
+
+func Hidden() CommandOption {
+	return func(c *command) {
+		c.hidden = true
+	}
+}
+
+// RawTrailingArg makes the last string parameter of the command receive the rest of the line as is
+func RawTrailingArg() CommandOption {
+	return func(c *command) {
+		c.rawTail = true
+	}
+}
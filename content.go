@@ -0,0 +1,221 @@
+package botkit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	DiceEmoji        = "🎲"
+	DartsEmoji       = "🎯"
+	BasketballEmoji  = "🏀"
+	FootballEmoji    = "⚽"
+	BowlingEmoji     = "🎳"
+	SlotMachineEmoji = "🎰"
+)
+
+type PollAnswerHandler func(ctx *Context, answer PollAnswer) error
+
+type Location struct {
+	Latitude             float64
+	Longitude            float64
+	HorizontalAccuracy   float64
+	LivePeriod           time.Duration
+	Heading              int
+	ProximityAlertRadius int
+}
+
+type Venue struct {
+	Latitude      float64
+	Longitude     float64
+	Title         string
+	Address       string
+	FoursquareID  string
+	GooglePlaceID string
+}
+
+type Contact struct {
+	PhoneNumber string
+	FirstName   string
+	LastName    string
+	VCard       string
+}
+
+type Poll struct {
+	Question              string
+	Options               []string
+	IsAnonymous           bool
+	AllowsMultipleAnswers bool
+	IsQuiz                bool
+	CorrectOption         int
+	Explanation           string
+	OpenPeriod            time.Duration
+}
+
+type PollAnswer struct {
+	PollID  string
+	Options []int
+}
+
+type messageContent interface {
+	method() string
+	addParams(params tgbotapi.Params) error
+}
+
+type dice string
+
+func NewLocation(latitude, longitude float64) *Location {
+	return &Location{
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+}
+
+// NewLiveLocation creates a location that can be updated for the given period (between 1 minute and 24 hours)
+func NewLiveLocation(latitude, longitude float64, period time.Duration) *Location {
+	return &Location{
+		Latitude:   latitude,
+		Longitude:  longitude,
+		LivePeriod: period,
+	}
+}
+
+func NewVenue(latitude, longitude float64, title, address string) *Venue {
+	return &Venue{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Title:     title,
+		Address:   address,
+	}
+}
+
+func NewContact(phoneNumber, firstName string) *Contact {
+	return &Contact{
+		PhoneNumber: phoneNumber,
+		FirstName:   firstName,
+	}
+}
+
+func NewPoll(question string, options ...string) *Poll {
+	return &Poll{
+		Question:    question,
+		Options:     options,
+		IsAnonymous: true,
+	}
+}
+
+func NewQuiz(question string, correctOption int, options ...string) *Poll {
+	return &Poll{
+		Question:      question,
+		Options:       options,
+		IsAnonymous:   true,
+		IsQuiz:        true,
+		CorrectOption: correctOption,
+	}
+}
+
+func (*Location) method() string {
+	return "sendLocation"
+}
+
+func (loc *Location) addParams(params tgbotapi.Params) error {
+	params["latitude"] = strconv.FormatFloat(loc.Latitude, 'f', -1, 64)
+	params["longitude"] = strconv.FormatFloat(loc.Longitude, 'f', -1, 64)
+	params.AddNonZeroFloat("horizontal_accuracy", loc.HorizontalAccuracy)
+	params.AddNonZero("live_period", int(loc.LivePeriod.Seconds()))
+	params.AddNonZero("heading", loc.Heading)
+	params.AddNonZero("proximity_alert_radius", loc.ProximityAlertRadius)
+	return nil
+}
+
+func (*Venue) method() string {
+	return "sendVenue"
+}
+
+func (venue *Venue) addParams(params tgbotapi.Params) error {
+	params["latitude"] = strconv.FormatFloat(venue.Latitude, 'f', -1, 64)
+	params["longitude"] = strconv.FormatFloat(venue.Longitude, 'f', -1, 64)
+	params["title"] = venue.Title
+	params["address"] = venue.Address
+	params.AddNonEmpty("foursquare_id", venue.FoursquareID)
+	params.AddNonEmpty("google_place_id", venue.GooglePlaceID)
+	return nil
+}
+
+func (*Contact) method() string {
+	return "sendContact"
+}
+
+func (contact *Contact) addParams(params tgbotapi.Params) error {
+	params["phone_number"] = contact.PhoneNumber
+	params["first_name"] = contact.FirstName
+	params.AddNonEmpty("last_name", contact.LastName)
+	params.AddNonEmpty("vcard", contact.VCard)
+	return nil
+}
+
+func (*Poll) method() string {
+	return "sendPoll"
+}
+
+func (poll *Poll) addParams(params tgbotapi.Params) error {
+	params["question"] = poll.Question
+	if err := params.AddInterface("options", poll.Options); err != nil {
+		return err
+	}
+	params["is_anonymous"] = strconv.FormatBool(poll.IsAnonymous)
+	params.AddBool("allows_multiple_answers", poll.AllowsMultipleAnswers)
+	if poll.IsQuiz {
+		params["type"] = "quiz"
+		params["correct_option_id"] = strconv.Itoa(poll.CorrectOption)
+		params.AddNonEmpty("explanation", poll.Explanation)
+	}
+	params.AddNonZero("open_period", int(poll.OpenPeriod.Seconds()))
+	return nil
+}
+
+func (dice) method() string {
+	return "sendDice"
+}
+
+func (d dice) addParams(params tgbotapi.Params) error {
+	params.AddNonEmpty("emoji", string(d))
+	return nil
+}
+
+func (bot *Bot) sendContent(ctx context.Context, chatID int64, content messageContent, replyID int, opts []SendOption) (Message, error) {
+	so := newSendOptions(replyID, opts)
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", chatID)
+	if err := content.addParams(params); err != nil {
+		return Message{}, err
+	}
+	if err := so.addParams(params); err != nil {
+		return Message{}, err
+	}
+	return bot.requestMessage(ctx, chatID, content.method(), params)
+}
+
+func (bot *Bot) editLiveLocation(ctx context.Context, msg Message, latitude, longitude float64) (Message, error) {
+	params := msg.toParams()
+	params["latitude"] = strconv.FormatFloat(latitude, 'f', -1, 64)
+	params["longitude"] = strconv.FormatFloat(longitude, 'f', -1, 64)
+	return bot.requestMessage(ctx, msg.ChatID, "editMessageLiveLocation", params)
+}
+
+func (bot *Bot) stopLiveLocation(ctx context.Context, msg Message) (Message, error) {
+	return bot.requestMessage(ctx, msg.ChatID, "stopMessageLiveLocation", msg.toParams())
+}
+
+func (bot *Bot) handlePollAnswer(ctx *Context, answer *tgbotapi.PollAnswer) error {
+	if bot.pollAnswerHandler == nil {
+		return nil
+	}
+	return bot.pollAnswerHandler(ctx, PollAnswer{
+		PollID:  answer.PollID,
+		Options: answer.OptionIDs,
+	})
+}
@@ -11,64 +11,191 @@ import (
 
 type Context struct {
 	context.Context
-	bot         *Bot
-	userID      int64
-	chatID      int64
-	replyID     int
-	dlg         *Dialog
-	taggedUsers []int64
-	isPrivate   bool
-}
-
-func newContext(bot *Bot, msg *tgbotapi.Message) *Context {
-	return &Context{
+	bot          *Bot
+	userID       int64
+	chatID       int64
+	senderChatID int64
+	replyID      int
+	dlg          *Dialog
+	taggedUsers  []int64
+	isPrivate    bool
+	languageCode string
+}
+
+func newContext(parent context.Context, bot *Bot, msg *tgbotapi.Message) *Context {
+	ctx := &Context{
+		Context:     parent,
 		bot:         bot,
-		userID:      msg.From.ID,
 		chatID:      msg.Chat.ID,
 		replyID:     msg.MessageID,
 		taggedUsers: getTaggedUsers(msg),
 		isPrivate:   msg.Chat.IsPrivate(),
 	}
+	// messages sent on behalf of a chat (channel posts, anonymous admins) have a sender chat
+	if msg.SenderChat != nil {
+		ctx.senderChatID = msg.SenderChat.ID
+	}
+	// channel posts have no sender user
+	if msg.From != nil {
+		ctx.userID = msg.From.ID
+		ctx.languageCode = msg.From.LanguageCode
+	} else if msg.SenderChat != nil {
+		ctx.userID = msg.SenderChat.ID
+	}
+	return ctx
+}
+
+func newCallbackContext(parent context.Context, bot *Bot, q *tgbotapi.CallbackQuery) *Context {
+	return &Context{
+		Context:      parent,
+		bot:          bot,
+		userID:       q.From.ID,
+		chatID:       q.Message.Chat.ID,
+		replyID:      q.Message.MessageID,
+		isPrivate:    q.Message.Chat.IsPrivate(),
+		languageCode: q.From.LanguageCode,
+	}
+}
+
+func newChatMemberContext(parent context.Context, bot *Bot, u *tgbotapi.ChatMemberUpdated) *Context {
+	ctx := &Context{
+		Context:   parent,
+		bot:       bot,
+		userID:    u.From.ID,
+		chatID:    u.Chat.ID,
+		isPrivate: u.Chat.IsPrivate(),
+	}
+	if user := u.NewChatMember.User; user != nil {
+		ctx.userID = user.ID
+		ctx.languageCode = user.LanguageCode
+	}
+	return ctx
+}
+
+func newUserContext(parent context.Context, bot *Bot, from *tgbotapi.User) *Context {
+	return &Context{
+		Context:      parent,
+		bot:          bot,
+		userID:       from.ID,
+		chatID:       from.ID,
+		isPrivate:    true,
+		languageCode: from.LanguageCode,
+	}
 }
 
 func (ctx *Context) StartDialog(name string) error {
 	return ctx.bot.startDialog(ctx, name)
 }
 
-func (ctx *Context) SendMessage(format string, args ...any) error {
-	return ctx.bot.sendMessage(ctx.chatID, fmt.Sprintf(format, args...), 0)
+func (ctx *Context) SendMessage(format string, args ...any) (Message, error) {
+	return ctx.bot.sendMessage(ctx, ctx.chatID, ctx.bot.sprintf(format, args...), 0)
+}
+
+func (ctx *Context) SendReply(format string, args ...any) (Message, error) {
+	return ctx.bot.sendMessage(ctx, ctx.chatID, ctx.bot.sprintf(format, args...), ctx.replyID)
+}
+
+func (ctx *Context) Send(text string, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendText(ctx, ctx.chatID, text, 0, opts)
+}
+
+func (ctx *Context) Reply(text string, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendText(ctx, ctx.chatID, text, ctx.replyID, opts)
+}
+
+func (ctx *Context) EditMessage(msg Message, text string, opts ...SendOption) (Message, error) {
+	return ctx.bot.editMessage(ctx, msg, text, opts)
+}
+
+func (ctx *Context) EditCaption(msg Message, caption string, opts ...SendOption) (Message, error) {
+	return ctx.bot.editCaption(ctx, msg, caption, opts)
+}
+
+func (ctx *Context) EditReplyMarkup(msg Message, kb Keyboard) (Message, error) {
+	return ctx.bot.editReplyMarkup(ctx, msg, kb)
+}
+
+func (ctx *Context) DeleteMessage(msg Message) error {
+	return ctx.bot.deleteMessage(ctx, msg)
 }
 
-func (ctx *Context) SendReply(format string, args ...any) error {
-	return ctx.bot.sendMessage(ctx.chatID, fmt.Sprintf(format, args...), ctx.replyID)
+func (ctx *Context) PinMessage(msg Message, opts ...SendOption) error {
+	return ctx.bot.pinMessage(ctx, msg, opts)
 }
 
-func (ctx *Context) SendMedia(media ...Media) error {
-	return ctx.bot.sendMedia(ctx.chatID, 0, media...)
+func (ctx *Context) UnpinMessage(msg Message) error {
+	return ctx.bot.unpinMessage(ctx, msg)
 }
 
-func (ctx *Context) ReplyMedia(media ...Media) error {
-	return ctx.bot.sendMedia(ctx.chatID, ctx.replyID, media...)
+func (ctx *Context) ForwardMessage(msg Message, opts ...SendOption) (Message, error) {
+	return ctx.bot.forwardMessage(ctx, ctx.chatID, msg, opts)
 }
 
-func (ctx *Context) SendSticker(stickerSet string, num int) error {
-	return ctx.bot.sendSticker(ctx.chatID, stickerSet, num, 0)
+func (ctx *Context) CopyMessage(msg Message, opts ...SendOption) (Message, error) {
+	return ctx.bot.copyMessage(ctx, ctx.chatID, msg, opts)
 }
 
-func (ctx *Context) ReplySticker(stickerSet string, num int) error {
-	return ctx.bot.sendSticker(ctx.chatID, stickerSet, num, ctx.replyID)
+func (ctx *Context) SendMedia(media ...Media) ([]Message, error) {
+	return ctx.bot.sendMedia(ctx, ctx.chatID, 0, media...)
 }
 
-func (ctx *Context) UploadFile(name string, r io.Reader) error {
-	return ctx.bot.uploadFile(ctx.chatID, name, r)
+func (ctx *Context) ReplyMedia(media ...Media) ([]Message, error) {
+	return ctx.bot.sendMedia(ctx, ctx.chatID, ctx.replyID, media...)
 }
 
-func (ctx *Context) UploadFileFromURL(url string) error {
-	return ctx.bot.uploadFileFromURL(ctx.chatID, url)
+func (ctx *Context) SendSticker(stickerSet string, num int) (Message, error) {
+	return ctx.bot.sendSticker(ctx, ctx.chatID, stickerSet, num, 0)
 }
 
+func (ctx *Context) ReplySticker(stickerSet string, num int) (Message, error) {
+	return ctx.bot.sendSticker(ctx, ctx.chatID, stickerSet, num, ctx.replyID)
+}
+
+func (ctx *Context) SendInvoice(inv *Invoice) (Message, error) {
+	return ctx.bot.sendInvoice(ctx, ctx.chatID, inv, 0)
+}
+
+func (ctx *Context) SendLocation(loc *Location, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendContent(ctx, ctx.chatID, loc, 0, opts)
+}
+
+func (ctx *Context) EditLiveLocation(msg Message, latitude, longitude float64) (Message, error) {
+	return ctx.bot.editLiveLocation(ctx, msg, latitude, longitude)
+}
+
+func (ctx *Context) StopLiveLocation(msg Message) (Message, error) {
+	return ctx.bot.stopLiveLocation(ctx, msg)
+}
+
+func (ctx *Context) SendVenue(venue *Venue, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendContent(ctx, ctx.chatID, venue, 0, opts)
+}
+
+func (ctx *Context) SendContact(contact *Contact, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendContent(ctx, ctx.chatID, contact, 0, opts)
+}
+
+func (ctx *Context) SendPoll(poll *Poll, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendContent(ctx, ctx.chatID, poll, 0, opts)
+}
+
+// SendDice sends an animated emoji with a random value, which is available in the returned message
+func (ctx *Context) SendDice(emoji string, opts ...SendOption) (Message, error) {
+	return ctx.bot.sendContent(ctx, ctx.chatID, dice(emoji), 0, opts)
+}
+
+func (ctx *Context) UploadFile(name string, r io.Reader) (Message, error) {
+	return ctx.bot.uploadFile(ctx, ctx.chatID, name, r)
+}
+
+func (ctx *Context) UploadFileFromURL(url string) (Message, error) {
+	return ctx.bot.uploadFileFromURL(ctx, ctx.chatID, url)
+}
+
+// DownloadFile returns a reader that is bound to the update, so it fails once the handler returns or the update times out.
+// Use Bot.DownloadFile to read the file later.
 func (ctx *Context) DownloadFile(fileID string) (io.ReadCloser, error) {
-	return ctx.bot.DownloadFile(fileID)
+	return ctx.bot.downloadFile(ctx, fileID)
 }
 
 func (ctx *Context) GetChatCache() (razcache.Cache, error) {
@@ -94,6 +221,23 @@ func (ctx *Context) GetChat() Chat {
 	return newChat(ctx.bot, ctx.chatID)
 }
 
+// GetMessage returns the handle of the message that triggered the update
+func (ctx *Context) GetMessage() Message {
+	return newMessage(ctx.bot, ctx.chatID, ctx.replyID)
+}
+
 func (ctx *Context) GetChatID() int64 {
 	return ctx.chatID
 }
+
+func (ctx *Context) GetUserID() int64 {
+	return ctx.userID
+}
+
+func (ctx *Context) IsPrivate() bool {
+	return ctx.isPrivate
+}
+
+func (ctx *Context) GetLanguageCode() string {
+	return ctx.languageCode
+}
@@ -54,13 +54,14 @@ func main() {
 
 	bot, err := botkit.NewBot(token,
 		//botkit.WithAPIEndpoint("localhost:8080"),
-		botkit.WithCommand("hello", cmdHelloWorld),
-		botkit.WithCommand("album", cmdAlbum),
-		botkit.WithCommand("startdlg", cmdStartDialog),
-		botkit.WithCommand("filedlg", cmdFileDialog),
-		botkit.WithCommand("sticker", cmdSticker),
-		botkit.WithCommand("sum", cmdSum),
-		botkit.WithCommand("sumMany", cmdSumMany),
+		botkit.WithCommand("hello", cmdHelloWorld, botkit.Description("Say hello")),
+		botkit.WithCommand("album", cmdAlbum, botkit.Description("Send a photo album")),
+		botkit.WithCommand("startdlg", cmdStartDialog, botkit.Description("Start a demo dialog")),
+		botkit.WithCommand("filedlg", cmdFileDialog, botkit.Description("Start a file upload dialog")),
+		botkit.WithCommand("sticker", cmdSticker, botkit.Description("Send a random sticker")),
+		botkit.WithCommand("sum", cmdSum, botkit.Description("Add two numbers"), botkit.Aliases("s")),
+		botkit.WithCommand("sumMany", cmdSumMany, botkit.Description("Add any amount of numbers")),
+		botkit.WithCommand("echo", cmdEcho, botkit.Description("Repeat the given text"), botkit.RawTrailingArg()),
 		botkit.WithDialog("dlg", dlg),
 		botkit.WithDialog("filedlg", filedlg),
 	)
@@ -111,3 +112,7 @@ func cmdSumMany(ctx *botkit.Context, numbers ...int) {
 	}
 	ctx.SendReply("%d", sum)
 }
+
+func cmdEcho(ctx *botkit.Context, text string) {
+	ctx.SendReply("%s", text)
+}
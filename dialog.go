@@ -1,6 +1,7 @@
 package botkit
 
 import (
+	"encoding/json"
 	"fmt"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -10,6 +11,7 @@ const (
 	dialogInputText dialogInputKind = iota
 	dialogInputCallback
 	dialogInputFile
+	dialogInputPayment
 )
 
 var errInvalidDialogInput = fmt.Errorf("invalid dialog input")
@@ -89,6 +91,18 @@ func (dlg *Dialog) LastUserChoices() ([]int, bool) {
 	return dlg.UserChoices(dlg.data.LastQuery)
 }
 
+func (dlg *Dialog) Payment(queryName string) (*Payment, bool) {
+	q := dlg.data.Queries[queryName]
+	if q == nil || q.Query.Kind != InvoiceQueryKind || len(q.UserResponse) == 0 {
+		return nil, false
+	}
+	payment := new(Payment)
+	if err := json.Unmarshal([]byte(q.UserResponse), payment); err != nil {
+		return nil, false
+	}
+	return payment, true
+}
+
 func (dlg *Dialog) handleInput(ctx *Context, kind dialogInputKind, data string) (updates []dialogMessage, isDone bool, err error) {
 	last := dlg.getQueryData(dlg.data.LastQuery)
 	if last == nil {
@@ -134,6 +148,13 @@ func (dlg *Dialog) handleInput(ctx *Context, kind dialogInputKind, data string)
 		last.UserResponse = data
 		last.ReplyID = ctx.replyID
 
+	case dialogInputPayment:
+		if last.Query.Kind != InvoiceQueryKind {
+			return nil, false, errInvalidDialogInput
+		}
+		last.UserResponse = data
+		last.ReplyID = ctx.replyID
+
 	default:
 		return nil, false, errInvalidDialogInput
 	}
@@ -148,6 +169,8 @@ func (dlg *Dialog) runHandler(ctx *Context) (updates []dialogMessage, isDone boo
 		if q.Kind == RetryQueryKind {
 			return updates, false
 		}
+		// handlers might return the same query to many dialogs, but the message ID is set per dialog
+		q = q.clone()
 		updates = append(updates, q)
 		dlg.setLastQuery(q)
 		return updates, false
@@ -0,0 +1,40 @@
+package botkit
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBuiltDialogConcurrentChats(t *testing.T) {
+	handler := NewDialogBuilder().
+		AddTextInputQuery("first", nil).
+		AddTextInputQuery("second", nil).
+		Build()
+
+	var wg sync.WaitGroup
+	for chatID := int64(1); chatID <= 2; chatID++ {
+		wg.Add(1)
+		go func(chatID int64) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				dlg := &Dialog{userID: chatID, chatID: chatID, handler: handler}
+				ctx := &Context{userID: chatID, chatID: chatID}
+				for step := 0; ; step++ {
+					updates, isDone := dlg.runHandler(ctx)
+					if isDone {
+						break
+					}
+					messageID := int(chatID)*1000 + step
+					for _, update := range updates {
+						update.setMessageID(messageID)
+					}
+					if q := dlg.LastQuery(); q.MessageID != messageID {
+						t.Errorf("chat %d: last query has message ID %d, want %d", chatID, q.MessageID, messageID)
+						return
+					}
+				}
+			}
+		}(chatID)
+	}
+	wg.Wait()
+}
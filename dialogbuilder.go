@@ -74,6 +74,17 @@ func (db *DialogBuilder) AddFileInputQuery(text string, validator func(io.Reader
 	return db
 }
 
+func (db *DialogBuilder) AddInvoiceQuery(invoice *Invoice, validator func(*Payment) error) *DialogBuilder {
+	h := func(resp any) error {
+		return validator(resp.(*Payment))
+	}
+	if validator == nil {
+		h = dummyDialogStepHandler
+	}
+	db.addStep(InvoiceQueryKind, invoice.Description, h).Invoice = invoice
+	return db
+}
+
 func (db *DialogBuilder) SetFinalizer(finalizer func(ctx *Context, responses []any)) *DialogBuilder {
 	db.finalizer = finalizer
 	return db
@@ -146,6 +157,9 @@ func (ds *dialogStep) getUserResponse(ctx *Context, dlg *Dialog) any {
 		resp, _ := dlg.UserResponse(ds.query.Name)
 		file, _ := ctx.DownloadFile(resp)
 		return file
+	case InvoiceQueryKind:
+		payment, _ := dlg.Payment(ds.query.Name)
+		return payment
 	default:
 		return nil
 	}
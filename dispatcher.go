@@ -0,0 +1,140 @@
+package botkit
+
+import (
+	"context"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const dispatcherQueueSize = 64
+
+type workerSlotKey struct{}
+
+// dispatcher runs the updates of each chat in order on a goroutine of their own,
+// while limiting the number of handlers running at once to the number of workers
+type dispatcher struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	chats   map[int64][]tgbotapi.Update
+	queued  chan struct{}
+	slots   chan struct{}
+	handler func(context.Context, tgbotapi.Update)
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// workerSlot is held by a running handler and can be given up while the handler is blocked on something else
+type workerSlot struct {
+	d        *dispatcher
+	mu       sync.Mutex
+	waiters  int
+	finished bool
+}
+
+func newDispatcher(ctx context.Context, workers int, handler func(context.Context, tgbotapi.Update)) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &dispatcher{
+		ctx:     ctx,
+		chats:   make(map[int64][]tgbotapi.Update),
+		queued:  make(chan struct{}, workers*dispatcherQueueSize),
+		slots:   make(chan struct{}, workers),
+		handler: handler,
+	}
+}
+
+// dispatch blocks while too many updates are queued
+func (d *dispatcher) dispatch(update tgbotapi.Update) bool {
+	d.queued <- struct{}{}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		<-d.queued
+		return false
+	}
+	chatID := getUpdateChatID(update)
+	queue, running := d.chats[chatID]
+	d.chats[chatID] = append(queue, update)
+	if !running {
+		d.wg.Add(1)
+		go d.work(chatID)
+	}
+	return true
+}
+
+func (d *dispatcher) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+}
+
+func (d *dispatcher) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *dispatcher) work(chatID int64) {
+	defer d.wg.Done()
+	for {
+		d.mu.Lock()
+		queue := d.chats[chatID]
+		if len(queue) == 0 {
+			delete(d.chats, chatID)
+			d.mu.Unlock()
+			return
+		}
+		update := queue[0]
+		d.chats[chatID] = queue[1:]
+		d.mu.Unlock()
+		<-d.queued
+
+		d.slots <- struct{}{}
+		slot := &workerSlot{d: d}
+		d.handler(context.WithValue(d.ctx, workerSlotKey{}, slot), update)
+		slot.finish()
+	}
+}
+
+// yieldWorker gives up the worker slot of the calling handler (if any) while wait blocks,
+// so updates of other chats are not held up by it
+func yieldWorker(ctx context.Context, wait func()) {
+	slot, _ := ctx.Value(workerSlotKey{}).(*workerSlot)
+	if slot == nil {
+		wait()
+		return
+	}
+	slot.mu.Lock()
+	if slot.waiters++; slot.waiters == 1 && !slot.finished {
+		<-slot.d.slots
+	}
+	slot.mu.Unlock()
+
+	wait()
+
+	slot.mu.Lock()
+	if slot.waiters--; slot.waiters == 0 && !slot.finished {
+		slot.d.slots <- struct{}{}
+	}
+	slot.mu.Unlock()
+}
+
+// finish releases the slot, unless goroutines started by the handler are still yielding it
+func (slot *workerSlot) finish() {
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	if slot.waiters == 0 {
+		<-slot.d.slots
+	}
+	slot.finished = true
+}
@@ -0,0 +1,114 @@
+package botkit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/razzie/razcache"
+)
+
+const fileIDCacheTTL = time.Hour * 24 * 30
+
+type fileMediaSource struct {
+	name   string
+	reader io.Reader
+	data   []byte
+	hash   string
+	fileID string
+}
+
+func (src *fileMediaSource) toRequestFileData() tgbotapi.RequestFileData {
+	switch {
+	case len(src.fileID) > 0:
+		return tgbotapi.FileID(src.fileID)
+	case src.data != nil:
+		return tgbotapi.FileBytes{Name: src.name, Bytes: src.data}
+	default:
+		return tgbotapi.FileReader{Name: src.name, Reader: src.reader}
+	}
+}
+
+// resolveCachedFiles reads file sources into memory to look up file IDs by the hash of their content
+func (bot *Bot) resolveCachedFiles(ctx context.Context, media []Media) error {
+	for _, m := range media {
+		src, ok := m.getFile().(*fileMediaSource)
+		if !ok || src.data != nil || len(src.fileID) > 0 {
+			continue
+		}
+		data, err := io.ReadAll(&contextReader{ctx: ctx, Reader: src.reader})
+		if rc, ok := src.reader.(io.Closer); ok {
+			rc.Close()
+		}
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		src.data = data
+		src.hash = m.mediaType() + ":" + hex.EncodeToString(sum[:])
+
+		fileID, err := bot.cache.Get("fileid:" + src.hash)
+		if err == nil {
+			src.fileID = fileID
+		} else if err != razcache.ErrNotFound {
+			bot.logger.Error("failed to load cached file ID", slog.String("hash", src.hash), slog.Any("err", err))
+		}
+	}
+	return nil
+}
+
+// cacheFileIDs expects one sent message per media, which is how telegram answers both single media and media groups
+func (bot *Bot) cacheFileIDs(media []Media, msgs []Message) {
+	for i, m := range media {
+		src, ok := m.getFile().(*fileMediaSource)
+		if !ok || len(src.hash) == 0 || len(src.fileID) > 0 || i >= len(msgs) || len(msgs[i].FileIDs) == 0 {
+			continue
+		}
+		src.fileID = msgs[i].FileIDs[0]
+		if err := bot.cache.Set("fileid:"+src.hash, src.fileID, fileIDCacheTTL); err != nil {
+			bot.logger.Error("failed to cache file ID", slog.String("hash", src.hash), slog.Any("err", err))
+		}
+	}
+}
+
+// withUploadFallback uploads the content of media again if telegram rejects a file ID that came from the cache
+func (bot *Bot) withUploadFallback(media []Media, send func() ([]Message, error)) ([]Message, error) {
+	msgs, err := send()
+	if !isFileIDError(err) || !bot.dropCachedFileIDs(media) {
+		return msgs, err
+	}
+	bot.logger.Warn("cached file ID rejected, uploading file again", slog.Any("err", err))
+	return send()
+}
+
+// other bad requests (like a caption that is too long) would fail again after uploading
+func isFileIDError(err error) bool {
+	tgErr, ok := err.(*tgbotapi.Error)
+	if !ok || tgErr.Code != http.StatusBadRequest {
+		return false
+	}
+	msg := strings.ToLower(tgErr.Message)
+	return strings.Contains(msg, "wrong file identifier") || strings.Contains(msg, "file_reference")
+}
+
+func (bot *Bot) dropCachedFileIDs(media []Media) bool {
+	var dropped bool
+	for _, m := range media {
+		src, ok := m.getFile().(*fileMediaSource)
+		if !ok || len(src.hash) == 0 || len(src.fileID) == 0 {
+			continue
+		}
+		src.fileID = ""
+		dropped = true
+		if err := bot.cache.Del("fileid:" + src.hash); err != nil && err != razcache.ErrNotFound {
+			bot.logger.Error("failed to delete cached file ID", slog.String("hash", src.hash), slog.Any("err", err))
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,27 @@
+package botkit
+
+import (
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestIsFileIDError(t *testing.T) {
+	tests := []struct {
+		err error
+		ok  bool
+	}{
+		{err: &tgbotapi.Error{Code: 400, Message: "Bad Request: wrong file identifier/HTTP URL specified"}, ok: true},
+		{err: &tgbotapi.Error{Code: 400, Message: "Bad Request: FILE_REFERENCE_EXPIRED"}, ok: true},
+		{err: &tgbotapi.Error{Code: 400, Message: "Bad Request: message caption is too long"}},
+		{err: &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 5"}},
+		{err: errors.New("wrong file identifier")},
+		{err: nil},
+	}
+	for _, tt := range tests {
+		if ok := isFileIDError(tt.err); ok != tt.ok {
+			t.Errorf("isFileIDError(%v) = %v, want %v", tt.err, ok, tt.ok)
+		}
+	}
+}
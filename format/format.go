@@ -0,0 +1,231 @@
+package format
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+type ParseMode string
+
+const (
+	PlainText  ParseMode = ""
+	MarkdownV2 ParseMode = "MarkdownV2"
+	HTML       ParseMode = "HTML"
+)
+
+type Text interface {
+	Render(mode ParseMode) string
+}
+
+type plain string
+
+type styled struct {
+	markdown string
+	tag      string
+	children []Text
+}
+
+type code string
+
+type pre struct {
+	text     string
+	language string
+}
+
+type link struct {
+	text string
+	url  string
+}
+
+type concat []Text
+
+type escaper struct {
+	mode  ParseMode
+	value any
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)",
+	"~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=",
+	"|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+)
+
+var markdownCodeEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+
+var markdownURLEscaper = strings.NewReplacer("\\", "\\\\", ")", "\\)")
+
+func Plain(text string) Text {
+	return plain(text)
+}
+
+func Bold(parts ...any) Text {
+	return &styled{markdown: "*", tag: "b", children: toTexts(parts)}
+}
+
+func Italic(parts ...any) Text {
+	return &styled{markdown: "_", tag: "i", children: toTexts(parts)}
+}
+
+func Underline(parts ...any) Text {
+	return &styled{markdown: "__", tag: "u", children: toTexts(parts)}
+}
+
+func Strikethrough(parts ...any) Text {
+	return &styled{markdown: "~", tag: "s", children: toTexts(parts)}
+}
+
+func Spoiler(parts ...any) Text {
+	return &styled{markdown: "||", tag: "tg-spoiler", children: toTexts(parts)}
+}
+
+func Code(text string) Text {
+	return code(text)
+}
+
+func Pre(text, language string) Text {
+	return &pre{text: text, language: language}
+}
+
+func Link(text, url string) Text {
+	return &link{text: text, url: url}
+}
+
+func Mention(name string, userID int64) Text {
+	return &link{text: name, url: "tg://user?id=" + strconv.FormatInt(userID, 10)}
+}
+
+// Concat joins parts into one text. Strings and other non-Text values are treated as plain text.
+func Concat(parts ...any) Text {
+	return concat(toTexts(parts))
+}
+
+func Escape(mode ParseMode, text string) string {
+	switch mode {
+	case MarkdownV2:
+		return markdownEscaper.Replace(text)
+	case HTML:
+		return html.EscapeString(text)
+	default:
+		return text
+	}
+}
+
+// Sprintf treats the format string as markup in the given parse mode and escapes every argument that is not a Text
+func Sprintf(mode ParseMode, format string, args ...any) string {
+	escapedArgs := make([]any, len(args))
+	for i, arg := range args {
+		if text, ok := arg.(Text); ok {
+			escapedArgs[i] = plainMarkup(text.Render(mode))
+		} else if mode == PlainText {
+			escapedArgs[i] = arg
+		} else {
+			escapedArgs[i] = &escaper{mode: mode, value: arg}
+		}
+	}
+	return fmt.Sprintf(format, escapedArgs...)
+}
+
+func (t plain) Render(mode ParseMode) string {
+	return Escape(mode, string(t))
+}
+
+func (t *styled) Render(mode ParseMode) string {
+	inner := concat(t.children).Render(mode)
+	switch mode {
+	case MarkdownV2:
+		return joinMarkdown(joinMarkdown(t.markdown, inner), t.markdown)
+	case HTML:
+		return "<" + t.tag + ">" + inner + "</" + t.tag + ">"
+	default:
+		return inner
+	}
+}
+
+func (t code) Render(mode ParseMode) string {
+	switch mode {
+	case MarkdownV2:
+		return "`" + markdownCodeEscaper.Replace(string(t)) + "`"
+	case HTML:
+		return "<code>" + html.EscapeString(string(t)) + "</code>"
+	default:
+		return string(t)
+	}
+}
+
+func (t *pre) Render(mode ParseMode) string {
+	switch mode {
+	case MarkdownV2:
+		return "```" + t.language + "\n" + markdownCodeEscaper.Replace(t.text) + "\n```"
+	case HTML:
+		if len(t.language) > 0 {
+			return `<pre><code class="language-` + html.EscapeString(t.language) + `">` + html.EscapeString(t.text) + "</code></pre>"
+		}
+		return "<pre>" + html.EscapeString(t.text) + "</pre>"
+	default:
+		return t.text
+	}
+}
+
+func (t *link) Render(mode ParseMode) string {
+	switch mode {
+	case MarkdownV2:
+		return "[" + markdownEscaper.Replace(t.text) + "](" + markdownURLEscaper.Replace(t.url) + ")"
+	case HTML:
+		return `<a href="` + html.EscapeString(t.url) + `">` + html.EscapeString(t.text) + "</a>"
+	default:
+		return t.text
+	}
+}
+
+func (t concat) Render(mode ParseMode) string {
+	var sb strings.Builder
+	for _, part := range t {
+		text := part.Render(mode)
+		if mode == MarkdownV2 && needsMarkdownSeparator(sb.String(), text) {
+			sb.WriteByte('\r')
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+// telegram greedily parses __ as an underline marker, so adjacent italic and underline markers
+// are separated by a \r, which it ignores
+func needsMarkdownSeparator(left, right string) bool {
+	return strings.HasSuffix(left, "_") && strings.HasPrefix(right, "_")
+}
+
+func joinMarkdown(left, right string) string {
+	if needsMarkdownSeparator(left, right) {
+		return left + "\r" + right
+	}
+	return left + right
+}
+
+func (e *escaper) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, Escape(e.mode, fmt.Sprintf(fmt.FormatString(f, verb), e.value)))
+}
+
+// plainMarkup is already rendered markup that Sprintf must not escape again
+type plainMarkup string
+
+func (m plainMarkup) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, string(m))
+}
+
+func toTexts(parts []any) []Text {
+	texts := make([]Text, len(parts))
+	for i, part := range parts {
+		switch part := part.(type) {
+		case Text:
+			texts[i] = part
+		case string:
+			texts[i] = plain(part)
+		default:
+			texts[i] = plain(fmt.Sprint(part))
+		}
+	}
+	return texts
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		mode ParseMode
+		in   string
+		out  string
+	}{
+		{PlainText, `_*[]()~` + "`" + `>#+-=|{}.!\<&"'`, `_*[]()~` + "`" + `>#+-=|{}.!\<&"'`},
+		{MarkdownV2, "_*[]()~`>#+-=|{}.!", "\\_\\*\\[\\]\\(\\)\\~\\`\\>\\#\\+\\-\\=\\|\\{\\}\\.\\!"},
+		{MarkdownV2, `a\b`, `a\\b`},
+		{MarkdownV2, "v1.2 (beta)!", "v1\\.2 \\(beta\\)\\!"},
+		{MarkdownV2, "héllo wörld", "héllo wörld"},
+		{HTML, `<b>&"'`, "&lt;b&gt;&amp;&#34;&#39;"},
+		{HTML, "_*[]()~`>#", "_*[]()~`&gt;#"},
+	}
+	for _, tt := range tests {
+		if out := Escape(tt.mode, tt.in); out != tt.out {
+			t.Errorf("Escape(%q, %q) = %q, want %q", tt.mode, tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		text     Text
+		plain    string
+		markdown string
+		html     string
+	}{
+		{Plain("1+1=2"), "1+1=2", "1\\+1\\=2", "1+1=2"},
+		{Bold("a.b"), "a.b", "*a\\.b*", "<b>a.b</b>"},
+		{Italic(Bold("x"), 1), "x1", "_*x*1_", "<i><b>x</b>1</i>"},
+		{Underline("u"), "u", "__u__", "<u>u</u>"},
+		{Italic(Underline("x")), "x", "_\r__x__\r_", "<i><u>x</u></i>"},
+		{Underline(Italic("x")), "x", "__\r_x_\r__", "<u><i>x</i></u>"},
+		{Strikethrough("s"), "s", "~s~", "<s>s</s>"},
+		{Spoiler("<s>"), "<s>", "||<s\\>||", "<tg-spoiler>&lt;s&gt;</tg-spoiler>"},
+		{Code("a`b\\c.d"), "a`b\\c.d", "`a\\`b\\\\c.d`", "<code>a`b\\c.d</code>"},
+		{Pre("x < 1", "go"), "x < 1", "```go\nx < 1\n```", `<pre><code class="language-go">x &lt; 1</code></pre>`},
+		{Pre("a`b", ""), "a`b", "```\na\\`b\n```", "<pre>a`b</pre>"},
+		{Link("a_b", "https://x.y/(z)"), "a_b", "[a\\_b](https://x.y/(z\\))", `<a href="https://x.y/(z)">a_b</a>`},
+		{Mention("Bob", 42), "Bob", "[Bob](tg://user?id=42)", `<a href="tg://user?id=42">Bob</a>`},
+		{Concat("a-", Bold("b"), 3), "a-b3", "a\\-*b*3", "a-<b>b</b>3"},
+		{Concat(Italic("a"), Italic("b")), "ab", "_a_\r_b_", "<i>a</i><i>b</i>"},
+	}
+	for _, tt := range tests {
+		for mode, want := range map[ParseMode]string{PlainText: tt.plain, MarkdownV2: tt.markdown, HTML: tt.html} {
+			if out := tt.text.Render(mode); out != want {
+				t.Errorf("Render(%q) = %q, want %q", mode, out, want)
+			}
+		}
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	tests := []struct {
+		mode   ParseMode
+		format string
+		args   []any
+		out    string
+	}{
+		{PlainText, "%v", []any{Bold("x")}, "x"},
+		{PlainText, "%s=%d", []any{"a.b", 1}, "a.b=1"},
+		{PlainText, "%q", []any{`a"b`}, `"a\"b"`},
+		{MarkdownV2, "*%s* %v", []any{"v1.2", Italic("i")}, "*v1\\.2* _i_"},
+		{MarkdownV2, "%5.1f%%", []any{-1.25}, " \\-1\\.2%"},
+		{MarkdownV2, "%d", []any{-3}, "\\-3"},
+		{HTML, "<b>%s</b> %v", []any{"<&>", Code("<")}, "<b>&lt;&amp;&gt;</b> <code>&lt;</code>"},
+	}
+	for _, tt := range tests {
+		if out := Sprintf(tt.mode, tt.format, tt.args...); out != tt.out {
+			t.Errorf("Sprintf(%q, %q) = %q, want %q", tt.mode, tt.format, out, tt.out)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package botkit
+
+import (
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var commandNameRegexp = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// telegram falls back to less specific scopes only if a scope has no commands set
+var commandScopeFallbacks = map[CommandScope][]CommandScope{
+	DefaultCommandScope: {DefaultCommandScope},
+	PrivateCommandScope: {DefaultCommandScope, PrivateCommandScope},
+	GroupCommandScope:   {DefaultCommandScope, GroupCommandScope},
+	AdminCommandScope:   {DefaultCommandScope, GroupCommandScope, AdminCommandScope},
+}
+
+func (bot *Bot) addHelpCommand() {
+	if !bot.helpCommand {
+		return
+	}
+	if _, ok := bot.commands["help"]; ok {
+		return
+	}
+	WithCommand("help", bot.cmdHelp, Description("Show available commands"))(&bot.BotOptions)
+}
+
+func (bot *Bot) cmdHelp(ctx *Context) CommandResponse {
+	scopes := []CommandScope{DefaultCommandScope, PrivateCommandScope}
+	if !ctx.isPrivate {
+		scopes = []CommandScope{DefaultCommandScope, GroupCommandScope, AdminCommandScope}
+	}
+
+	var lines []string
+	for _, c := range bot.getSortedCommands() {
+		if c.hidden || !c.isInScope(scopes...) {
+			continue
+		}
+		line := c.usage
+		for _, alias := range c.aliases {
+			line += ", /" + alias
+		}
+		if desc := c.getDescription(ctx.languageCode); len(desc) > 0 {
+			line += " - " + desc
+		}
+		lines = append(lines, line)
+	}
+	return SendReply("%s", strings.Join(lines, "\n"))
+}
+
+func (bot *Bot) setCommandMenu() {
+	if !bot.commandMenu {
+		return
+	}
+
+	commands := bot.getSortedCommands()
+	languageCodes := []string{""}
+	usedScopes := map[CommandScope]bool{DefaultCommandScope: true}
+	for _, c := range commands {
+		for languageCode := range c.descriptions {
+			languageCodes = append(languageCodes, languageCode)
+		}
+		for _, scope := range c.scopes {
+			usedScopes[scope] = true
+		}
+	}
+	slices.Sort(languageCodes)
+	languageCodes = slices.Compact(languageCodes)
+
+	for scope := range usedScopes {
+		for _, languageCode := range languageCodes {
+			var botCommands []tgbotapi.BotCommand
+			for _, c := range commands {
+				if c.hidden || !c.isInScope(commandScopeFallbacks[scope]...) {
+					continue
+				}
+				if !commandNameRegexp.MatchString(c.name) {
+					bot.logger.Warn("command cannot be added to the menu", slog.String("cmd", c.name))
+					continue
+				}
+				desc := c.getDescription(languageCode)
+				if len(desc) == 0 {
+					desc = c.usage
+				}
+				botCommands = append(botCommands, tgbotapi.BotCommand{Command: c.name, Description: desc})
+			}
+			if len(botCommands) == 0 {
+				continue
+			}
+			cfg := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(tgbotapi.BotCommandScope{Type: string(scope)}, languageCode, botCommands...)
+			if _, err := bot.api.Request(cfg); err != nil {
+				bot.logger.Error("failed to set command menu",
+					slog.String("scope", string(scope)),
+					slog.String("languageCode", languageCode),
+					slog.Any("err", err))
+			}
+		}
+	}
+}
+
+func (bot *Bot) getSortedCommands() []*command {
+	commands := make([]*command, 0, len(bot.commands))
+	for _, c := range bot.commands {
+		commands = append(commands, c)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].name < commands[j].name
+	})
+	return commands
+}
@@ -0,0 +1,311 @@
+package botkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxInlineResults = 50
+
+type InlineQueryHandler func(ctx *InlineContext, query string) []InlineResult
+
+type ChosenInlineResultHandler func(ctx *InlineContext, resultID, query string) error
+
+type InlineContext struct {
+	context.Context
+	bot          *Bot
+	queryID      string
+	userID       int64
+	languageCode string
+	chatType     string
+	offset       string
+	nextOffset   string
+	cacheTime    time.Duration
+	isPersonal   bool
+}
+
+type InlineResult interface {
+	toInlineResult() (any, error)
+}
+
+type ArticleResult struct {
+	ID          string
+	Title       string
+	Text        string
+	Description string
+	URL         string
+	ThumbURL    string
+}
+
+type PhotoResult struct {
+	ID          string
+	Title       string
+	Description string
+	Photo       *Photo
+}
+
+type VideoResult struct {
+	ID          string
+	Title       string
+	Description string
+	MimeType    string
+	Video       *Video
+}
+
+type AudioResult struct {
+	ID    string
+	Audio *Audio
+}
+
+type DocumentResult struct {
+	ID          string
+	Title       string
+	Description string
+	MimeType    string
+	Document    *Document
+}
+
+func newInlineContext(parent context.Context, bot *Bot, q *tgbotapi.InlineQuery) *InlineContext {
+	return &InlineContext{
+		Context:      parent,
+		bot:          bot,
+		queryID:      q.ID,
+		userID:       q.From.ID,
+		languageCode: q.From.LanguageCode,
+		chatType:     q.ChatType,
+		offset:       q.Offset,
+		cacheTime:    bot.inlineCacheTime,
+	}
+}
+
+func newChosenInlineContext(parent context.Context, bot *Bot, r *tgbotapi.ChosenInlineResult) *InlineContext {
+	return &InlineContext{
+		Context:      parent,
+		bot:          bot,
+		userID:       r.From.ID,
+		languageCode: r.From.LanguageCode,
+	}
+}
+
+func (ctx *InlineContext) GetUserID() int64 {
+	return ctx.userID
+}
+
+func (ctx *InlineContext) GetLanguageCode() string {
+	return ctx.languageCode
+}
+
+func (ctx *InlineContext) GetChatType() string {
+	return ctx.chatType
+}
+
+func (ctx *InlineContext) Offset() string {
+	return ctx.offset
+}
+
+// SetNextOffset disables automatic pagination and passes the offset to the next inline query of the user
+func (ctx *InlineContext) SetNextOffset(offset string) {
+	ctx.nextOffset = offset
+}
+
+func (ctx *InlineContext) SetCacheTime(cacheTime time.Duration) {
+	ctx.cacheTime = cacheTime
+}
+
+func (ctx *InlineContext) SetPersonal(isPersonal bool) {
+	ctx.isPersonal = isPersonal
+}
+
+func NewArticleResult(id, title, text string) *ArticleResult {
+	return &ArticleResult{
+		ID:    id,
+		Title: title,
+		Text:  text,
+	}
+}
+
+func NewPhotoResult(id string, photo *Photo) *PhotoResult {
+	return &PhotoResult{
+		ID:    id,
+		Photo: photo,
+	}
+}
+
+func NewVideoResult(id, title string, video *Video) *VideoResult {
+	return &VideoResult{
+		ID:       id,
+		Title:    title,
+		MimeType: "video/mp4",
+		Video:    video,
+	}
+}
+
+func NewAudioResult(id string, audio *Audio) *AudioResult {
+	return &AudioResult{
+		ID:    id,
+		Audio: audio,
+	}
+}
+
+func NewDocumentResult(id, title string, doc *Document) *DocumentResult {
+	return &DocumentResult{
+		ID:       id,
+		Title:    title,
+		MimeType: "application/pdf",
+		Document: doc,
+	}
+}
+
+func (r *ArticleResult) toInlineResult() (any, error) {
+	result := tgbotapi.NewInlineQueryResultArticle(r.ID, r.Title, r.Text)
+	result.Description = r.Description
+	result.URL = r.URL
+	result.ThumbURL = r.ThumbURL
+	return result, nil
+}
+
+func (r *PhotoResult) toInlineResult() (any, error) {
+	url, fileID, err := getInlineSource(r.Photo.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileID) > 0 {
+		result := tgbotapi.NewInlineQueryResultCachedPhoto(r.ID, fileID)
+		result.Title = r.Title
+		result.Description = r.Description
+		result.Caption = r.Photo.Caption
+		return result, nil
+	}
+	result := tgbotapi.NewInlineQueryResultPhoto(r.ID, url)
+	result.ThumbURL = getInlineThumbURL(r.Photo.Thumb, url)
+	result.Title = r.Title
+	result.Description = r.Description
+	result.Caption = r.Photo.Caption
+	return result, nil
+}
+
+func (r *VideoResult) toInlineResult() (any, error) {
+	url, fileID, err := getInlineSource(r.Video.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileID) > 0 {
+		result := tgbotapi.NewInlineQueryResultCachedVideo(r.ID, fileID, r.Title)
+		result.Description = r.Description
+		result.Caption = r.Video.Caption
+		return result, nil
+	}
+	// telegram rejects the whole answer if a video result has no thumbnail
+	thumbURL := getInlineThumbURL(r.Video.Thumb, "")
+	if len(thumbURL) == 0 {
+		return nil, fmt.Errorf("video result %q needs a thumbnail URL", r.ID)
+	}
+	result := tgbotapi.NewInlineQueryResultVideo(r.ID, url)
+	result.MimeType = r.MimeType
+	result.ThumbURL = thumbURL
+	result.Title = r.Title
+	result.Description = r.Description
+	result.Caption = r.Video.Caption
+	result.Duration = r.Video.Duration
+	return result, nil
+}
+
+func (r *AudioResult) toInlineResult() (any, error) {
+	url, fileID, err := getInlineSource(r.Audio.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileID) > 0 {
+		result := tgbotapi.NewInlineQueryResultCachedAudio(r.ID, fileID)
+		result.Caption = r.Audio.Caption
+		return result, nil
+	}
+	result := tgbotapi.NewInlineQueryResultAudio(r.ID, url, r.Audio.Title)
+	result.Caption = r.Audio.Caption
+	result.Performer = r.Audio.Performer
+	result.Duration = r.Audio.Duration
+	return result, nil
+}
+
+func (r *DocumentResult) toInlineResult() (any, error) {
+	url, fileID, err := getInlineSource(r.Document.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileID) > 0 {
+		result := tgbotapi.NewInlineQueryResultCachedDocument(r.ID, fileID, r.Title)
+		result.Description = r.Description
+		result.Caption = r.Document.Caption
+		return result, nil
+	}
+	result := tgbotapi.NewInlineQueryResultDocument(r.ID, url, r.Title, r.MimeType)
+	result.Description = r.Description
+	result.Caption = r.Document.Caption
+	result.ThumbURL = getInlineThumbURL(r.Document.Thumb, "")
+	return result, nil
+}
+
+func (bot *Bot) handleInlineQuery(ctx *InlineContext, q *tgbotapi.InlineQuery) {
+	results := bot.inlineQueryHandler(ctx, q.Query)
+
+	// paginate automatically if the handler returned every result at once
+	if len(ctx.nextOffset) == 0 && len(results) > maxInlineResults {
+		offset, _ := strconv.Atoi(ctx.offset)
+		offset = min(max(offset, 0), len(results))
+		end := min(offset+maxInlineResults, len(results))
+		if end < len(results) {
+			ctx.nextOffset = strconv.Itoa(end)
+		}
+		results = results[offset:end]
+	}
+
+	inlineResults := make([]any, 0, len(results))
+	for _, result := range results {
+		inlineResult, err := result.toInlineResult()
+		if err != nil {
+			bot.logger.Error("invalid inline result", slog.String("queryID", q.ID), slog.Any("err", err))
+			continue
+		}
+		inlineResults = append(inlineResults, inlineResult)
+	}
+
+	cfg := tgbotapi.InlineConfig{
+		InlineQueryID: q.ID,
+		Results:       inlineResults,
+		CacheTime:     int(ctx.cacheTime.Seconds()),
+		IsPersonal:    ctx.isPersonal,
+		NextOffset:    ctx.nextOffset,
+	}
+	if _, err := bot.api.Request(cfg); err != nil {
+		bot.logger.Error("failed to answer inline query", slog.String("queryID", q.ID), slog.Any("err", err))
+	}
+}
+
+// inline results can only refer to files by URL or by file ID
+func getInlineSource(src MediaSource) (url, fileID string, err error) {
+	if src == nil {
+		return "", "", fmt.Errorf("missing media source")
+	}
+	switch data := src.toRequestFileData().(type) {
+	case tgbotapi.FileURL:
+		return string(data), "", nil
+	case tgbotapi.FileID:
+		return "", string(data), nil
+	default:
+		return "", "", fmt.Errorf("inline results only support URL or file ID sources")
+	}
+}
+
+func getInlineThumbURL(thumb MediaSource, fallback string) string {
+	if thumb != nil {
+		if data, ok := thumb.toRequestFileData().(tgbotapi.FileURL); ok {
+			return string(data)
+		}
+	}
+	return fallback
+}
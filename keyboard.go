@@ -0,0 +1,97 @@
+package botkit
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type Keyboard interface {
+	toReplyMarkup() any
+}
+
+type InlineButton struct {
+	Text string
+	Data string
+	URL  string
+}
+
+type InlineKeyboard struct {
+	Rows [][]InlineButton
+}
+
+type ReplyKeyboard struct {
+	Rows        [][]string
+	OneTime     bool
+	Resize      bool
+	Placeholder string
+	Selective   bool
+}
+
+type removeKeyboard struct{}
+
+// CallbackButton creates a button whose data is passed to the callback query handler when pressed
+func CallbackButton(text, data string) InlineButton {
+	return InlineButton{
+		Text: text,
+		Data: data,
+	}
+}
+
+func URLButton(text, url string) InlineButton {
+	return InlineButton{
+		Text: text,
+		URL:  url,
+	}
+}
+
+func NewInlineKeyboard(rows ...[]InlineButton) *InlineKeyboard {
+	return &InlineKeyboard{
+		Rows: rows,
+	}
+}
+
+func NewReplyKeyboard(rows ...[]string) *ReplyKeyboard {
+	return &ReplyKeyboard{
+		Rows:   rows,
+		Resize: true,
+	}
+}
+
+func RemoveKeyboard() Keyboard {
+	return removeKeyboard{}
+}
+
+func (kb *InlineKeyboard) toReplyMarkup() any {
+	rows := make([][]tgbotapi.InlineKeyboardButton, len(kb.Rows))
+	for i, row := range kb.Rows {
+		rows[i] = make([]tgbotapi.InlineKeyboardButton, len(row))
+		for j, btn := range row {
+			if len(btn.URL) > 0 {
+				rows[i][j] = tgbotapi.NewInlineKeyboardButtonURL(btn.Text, btn.URL)
+			} else {
+				rows[i][j] = tgbotapi.NewInlineKeyboardButtonData(btn.Text, btn.Data)
+			}
+		}
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (kb *ReplyKeyboard) toReplyMarkup() any {
+	rows := make([][]tgbotapi.KeyboardButton, len(kb.Rows))
+	for i, row := range kb.Rows {
+		rows[i] = make([]tgbotapi.KeyboardButton, len(row))
+		for j, text := range row {
+			rows[i][j] = tgbotapi.NewKeyboardButton(text)
+		}
+	}
+	return tgbotapi.ReplyKeyboardMarkup{
+		Keyboard:              rows,
+		ResizeKeyboard:        kb.Resize,
+		OneTimeKeyboard:       kb.OneTime,
+		InputFieldPlaceholder: kb.Placeholder,
+		Selective:             kb.Selective,
+	}
+}
+
+func (removeKeyboard) toReplyMarkup() any {
+	return tgbotapi.NewRemoveKeyboard(true)
+}
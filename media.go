@@ -1,14 +1,42 @@
 package botkit
 
 import (
+	"encoding/json"
 	"io"
+	"reflect"
+	"strconv"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/razzie/botkit/format"
 )
 
+const maxMediaGroupSize = 10
+
+// media of the same group kind can be sent together as a media group
+var mediaGroupKinds = map[string]string{
+	"photo":    "visual",
+	"video":    "visual",
+	"audio":    "audio",
+	"document": "document",
+}
+
+var mediaMethods = map[string]string{
+	"photo":      "sendPhoto",
+	"video":      "sendVideo",
+	"audio":      "sendAudio",
+	"document":   "sendDocument",
+	"animation":  "sendAnimation",
+	"voice":      "sendVoice",
+	"video_note": "sendVideoNote",
+	"sticker":    "sendSticker",
+}
+
 type Media interface {
-	toChattable(chatID int64, replyID int) tgbotapi.Chattable
-	toInputMedia() any
+	mediaType() string
+	getFile() MediaSource
+	getThumb() MediaSource
+	getFields() mediaFields
+	isReusable() bool
 }
 
 type MediaSource interface {
@@ -20,19 +48,25 @@ type wrapperMediaSource struct {
 }
 
 type BaseMedia struct {
-	File    MediaSource
-	Thumb   MediaSource
-	Caption string
+	File            MediaSource
+	Thumb           MediaSource
+	Caption         string
+	ParseMode       format.ParseMode
+	CaptionEntities []tgbotapi.MessageEntity
 }
 
 type Photo struct {
 	BaseMedia
+	HasSpoiler bool
 }
 
 type Video struct {
 	BaseMedia
 	Duration          int
+	Width             int
+	Height            int
 	SupportsStreaming bool
+	HasSpoiler        bool
 }
 
 type Audio struct {
@@ -42,96 +76,318 @@ type Audio struct {
 	Title     string
 }
 
+type Document struct {
+	BaseMedia
+	DisableContentTypeDetection bool
+}
+
+type Animation struct {
+	BaseMedia
+	Duration   int
+	Width      int
+	Height     int
+	HasSpoiler bool
+}
+
+// Voice messages have no thumbnail
+type Voice struct {
+	BaseMedia
+	Duration int
+}
+
+// VideoNote messages have no caption
+type VideoNote struct {
+	BaseMedia
+	Duration int
+	Length   int
+}
+
+type Sticker struct {
+	File  MediaSource
+	Emoji string
+}
+
+// mediaFields holds the non-zero optional fields of a media, which are the same for single media and media group items
+type mediaFields map[string]any
+
 func FileSource(name string, r io.Reader) MediaSource {
-	return &wrapperMediaSource{data: tgbotapi.FileReader{Name: name, Reader: r}}
+	return &fileMediaSource{name: name, reader: r}
 }
 
 func URLSource(url string) MediaSource {
 	return &wrapperMediaSource{data: tgbotapi.FileURL(url)}
 }
 
+// FileIDSource refers to a file that is already on the telegram servers, like the file IDs of a sent Message
+func FileIDSource(id string) MediaSource {
+	return &wrapperMediaSource{data: tgbotapi.FileID(id)}
+}
+
 func NewPhoto(file MediaSource) *Photo {
 	return &Photo{
 		BaseMedia: BaseMedia{File: file},
 	}
 }
 
-func (photo *Photo) toChattable(chatID int64, replyID int) tgbotapi.Chattable {
-	cfg := tgbotapi.NewPhoto(chatID, photo.File.toRequestFileData())
-	if photo.Thumb != nil {
-		cfg.Thumb = photo.Thumb.toRequestFileData()
+func NewVideo(file MediaSource) *Video {
+	return &Video{
+		BaseMedia: BaseMedia{File: file},
 	}
-	cfg.Caption = photo.Caption
-	cfg.ReplyToMessageID = replyID
-	return cfg
 }
 
-func (photo *Photo) toInputMedia() any {
-	cfg := tgbotapi.NewInputMediaPhoto(photo.File.toRequestFileData())
-	cfg.Caption = photo.Caption
-	return cfg
+func NewAudio(file MediaSource) *Audio {
+	return &Audio{
+		BaseMedia: BaseMedia{File: file},
+	}
 }
 
-func NewVideo(file MediaSource) *Video {
-	return &Video{
+func NewDocument(file MediaSource) *Document {
+	return &Document{
 		BaseMedia: BaseMedia{File: file},
 	}
 }
 
-func (video *Video) toChattable(chatID int64, replyID int) tgbotapi.Chattable {
-	cfg := tgbotapi.NewVideo(chatID, video.File.toRequestFileData())
-	if video.Thumb != nil {
-		cfg.Thumb = video.Thumb.toRequestFileData()
+func NewAnimation(file MediaSource) *Animation {
+	return &Animation{
+		BaseMedia: BaseMedia{File: file},
 	}
-	cfg.Caption = video.Caption
-	cfg.ReplyToMessageID = replyID
-	cfg.Duration = video.Duration
-	cfg.SupportsStreaming = video.SupportsStreaming
-	return cfg
 }
 
-func (video *Video) toInputMedia() any {
-	cfg := tgbotapi.NewInputMediaVideo(video.File.toRequestFileData())
-	if video.Thumb != nil {
-		cfg.Thumb = video.Thumb.toRequestFileData()
+func NewVoice(file MediaSource) *Voice {
+	return &Voice{
+		BaseMedia: BaseMedia{File: file},
 	}
-	cfg.Caption = video.Caption
-	cfg.Duration = video.Duration
-	cfg.SupportsStreaming = video.SupportsStreaming
-	return cfg
 }
 
-func NewAudio(file MediaSource) *Audio {
-	return &Audio{
+func NewVideoNote(file MediaSource) *VideoNote {
+	return &VideoNote{
 		BaseMedia: BaseMedia{File: file},
 	}
 }
 
-func (audio *Audio) toChattable(chatID int64, replyID int) tgbotapi.Chattable {
-	cfg := tgbotapi.NewAudio(chatID, audio.File.toRequestFileData())
-	if audio.Thumb != nil {
-		cfg.Thumb = audio.Thumb.toRequestFileData()
+func NewSticker(file MediaSource) *Sticker {
+	return &Sticker{
+		File: file,
 	}
-	cfg.Caption = audio.Caption
-	cfg.ReplyToMessageID = replyID
-	cfg.Duration = audio.Duration
-	cfg.Performer = audio.Performer
-	cfg.Title = audio.Title
-	return cfg
 }
 
-func (audio *Audio) toInputMedia() any {
-	cfg := tgbotapi.NewInputMediaAudio(audio.File.toRequestFileData())
-	if audio.Thumb != nil {
-		cfg.Thumb = audio.Thumb.toRequestFileData()
+func (*Photo) mediaType() string {
+	return "photo"
+}
+
+func (photo *Photo) getFields() mediaFields {
+	fields := photo.getCaptionFields()
+	fields.add("has_spoiler", photo.HasSpoiler)
+	return fields
+}
+
+func (*Video) mediaType() string {
+	return "video"
+}
+
+func (video *Video) getFields() mediaFields {
+	fields := video.getCaptionFields()
+	fields.add("duration", video.Duration)
+	fields.add("width", video.Width)
+	fields.add("height", video.Height)
+	fields.add("supports_streaming", video.SupportsStreaming)
+	fields.add("has_spoiler", video.HasSpoiler)
+	return fields
+}
+
+func (*Audio) mediaType() string {
+	return "audio"
+}
+
+func (audio *Audio) getFields() mediaFields {
+	fields := audio.getCaptionFields()
+	fields.add("duration", audio.Duration)
+	fields.add("performer", audio.Performer)
+	fields.add("title", audio.Title)
+	return fields
+}
+
+func (*Document) mediaType() string {
+	return "document"
+}
+
+func (doc *Document) getFields() mediaFields {
+	fields := doc.getCaptionFields()
+	fields.add("disable_content_type_detection", doc.DisableContentTypeDetection)
+	return fields
+}
+
+func (*Animation) mediaType() string {
+	return "animation"
+}
+
+func (anim *Animation) getFields() mediaFields {
+	fields := anim.getCaptionFields()
+	fields.add("duration", anim.Duration)
+	fields.add("width", anim.Width)
+	fields.add("height", anim.Height)
+	fields.add("has_spoiler", anim.HasSpoiler)
+	return fields
+}
+
+func (*Voice) mediaType() string {
+	return "voice"
+}
+
+func (*Voice) getThumb() MediaSource {
+	return nil
+}
+
+func (voice *Voice) getFields() mediaFields {
+	fields := voice.getCaptionFields()
+	fields.add("duration", voice.Duration)
+	return fields
+}
+
+func (*VideoNote) mediaType() string {
+	return "video_note"
+}
+
+func (note *VideoNote) getFields() mediaFields {
+	fields := make(mediaFields)
+	fields.add("duration", note.Duration)
+	fields.add("length", note.Length)
+	return fields
+}
+
+func (*Sticker) mediaType() string {
+	return "sticker"
+}
+
+func (sticker *Sticker) getFile() MediaSource {
+	return sticker.File
+}
+
+func (*Sticker) getThumb() MediaSource {
+	return nil
+}
+
+func (sticker *Sticker) getFields() mediaFields {
+	fields := make(mediaFields)
+	fields.add("emoji", sticker.Emoji)
+	return fields
+}
+
+func (sticker *Sticker) isReusable() bool {
+	return isReusableSource(sticker.File)
+}
+
+func (media *BaseMedia) getFile() MediaSource {
+	return media.File
+}
+
+func (media *BaseMedia) getThumb() MediaSource {
+	return media.Thumb
+}
+
+func (media *BaseMedia) getCaptionFields() mediaFields {
+	fields := make(mediaFields)
+	fields.add("caption", media.Caption)
+	fields.add("parse_mode", string(media.ParseMode))
+	fields.add("caption_entities", media.CaptionEntities)
+	return fields
+}
+
+func (media *BaseMedia) isReusable() bool {
+	return isReusableSource(media.File) && isReusableSource(media.Thumb)
+}
+
+func isReusableSource(src MediaSource) bool {
+	if src == nil {
+		return true
 	}
-	cfg.Caption = audio.Caption
-	cfg.Duration = audio.Duration
-	cfg.Performer = audio.Performer
-	cfg.Title = audio.Title
-	return cfg
+	_, isReader := src.toRequestFileData().(tgbotapi.FileReader)
+	return !isReader
 }
 
 func (w wrapperMediaSource) toRequestFileData() tgbotapi.RequestFileData {
 	return w.data
 }
+
+func (fields mediaFields) add(key string, value any) {
+	if !reflect.ValueOf(value).IsZero() {
+		fields[key] = value
+	}
+}
+
+func (fields mediaFields) addParams(params tgbotapi.Params) error {
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			params[key] = s
+			continue
+		}
+		if err := params.AddInterface(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func canGroupMedia(media []Media) bool {
+	kind := mediaGroupKinds[media[0].mediaType()]
+	if len(kind) == 0 {
+		return false
+	}
+	for _, m := range media[1:] {
+		if mediaGroupKinds[m.mediaType()] != kind {
+			return false
+		}
+	}
+	return true
+}
+
+func getMediaParams(m Media) (tgbotapi.Params, []tgbotapi.RequestFile, error) {
+	params := make(tgbotapi.Params)
+	if err := m.getFields().addParams(params); err != nil {
+		return nil, nil, err
+	}
+	var files []tgbotapi.RequestFile
+	for field, src := range map[string]MediaSource{m.mediaType(): m.getFile(), "thumbnail": m.getThumb()} {
+		if src == nil {
+			continue
+		}
+		data := src.toRequestFileData()
+		if data.NeedsUpload() {
+			files = append(files, tgbotapi.RequestFile{Name: field, Data: data})
+		} else {
+			params[field] = data.SendData()
+		}
+	}
+	return params, files, nil
+}
+
+// getMediaGroupParams attaches uploaded files by name, as media group items are sent as a single JSON field
+func getMediaGroupParams(media []Media) (tgbotapi.Params, []tgbotapi.RequestFile, error) {
+	var files []tgbotapi.RequestFile
+	attach := func(name string, src MediaSource) string {
+		data := src.toRequestFileData()
+		if !data.NeedsUpload() {
+			return data.SendData()
+		}
+		files = append(files, tgbotapi.RequestFile{Name: name, Data: data})
+		return "attach://" + name
+	}
+
+	items := make([]mediaFields, len(media))
+	for i, m := range media {
+		item := m.getFields()
+		item["type"] = m.mediaType()
+		item["media"] = attach("file-"+strconv.Itoa(i), m.getFile())
+		if thumb := m.getThumb(); thumb != nil {
+			item["thumbnail"] = attach("thumbnail-"+strconv.Itoa(i), thumb)
+		}
+		items[i] = item
+	}
+
+	itemsJson, err := json.Marshal(items)
+	if err != nil {
+		return nil, nil, err
+	}
+	params := tgbotapi.Params{"media": string(itemsJson)}
+	return params, files, nil
+}
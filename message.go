@@ -0,0 +1,225 @@
+package botkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var errUnboundMessage = errors.New("message handle is not bound to a bot")
+
+// Message is a handle of a message that can be stored and used later to edit, delete or forward the message.
+// Handles restored from storage have to be bound again with Bot.BindMessage before calling their methods.
+type Message struct {
+	bot       *Bot
+	ChatID    int64    `json:"chat_id"`
+	MessageID int      `json:"message_id"`
+	FileIDs   []string `json:"file_ids,omitempty"`
+	PollID    string   `json:"poll_id,omitempty"`
+	DiceValue int      `json:"dice_value,omitempty"`
+}
+
+func newMessage(bot *Bot, chatID int64, messageID int) Message {
+	return Message{
+		bot:       bot,
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+}
+
+func newSentMessage(bot *Bot, msg *tgbotapi.Message) Message {
+	m := newMessage(bot, msg.Chat.ID, msg.MessageID)
+	m.FileIDs = getFileIDsFromMessage(msg)
+	// the largest photo size is the one worth sending again
+	if len(msg.Photo) > 0 {
+		m.FileIDs[0] = msg.Photo[len(msg.Photo)-1].FileID
+	}
+	if msg.Poll != nil {
+		m.PollID = msg.Poll.ID
+	}
+	if msg.Dice != nil {
+		m.DiceValue = msg.Dice.Value
+	}
+	return m
+}
+
+func (bot *Bot) BindMessage(msg Message) Message {
+	msg.bot = bot
+	return msg
+}
+
+func (msg Message) Edit(text string, opts ...SendOption) (Message, error) {
+	if msg.bot == nil {
+		return Message{}, errUnboundMessage
+	}
+	return msg.bot.editMessage(msg.bot.ctx, msg, text, opts)
+}
+
+func (msg Message) EditCaption(caption string, opts ...SendOption) (Message, error) {
+	if msg.bot == nil {
+		return Message{}, errUnboundMessage
+	}
+	return msg.bot.editCaption(msg.bot.ctx, msg, caption, opts)
+}
+
+func (msg Message) EditReplyMarkup(kb Keyboard) (Message, error) {
+	if msg.bot == nil {
+		return Message{}, errUnboundMessage
+	}
+	return msg.bot.editReplyMarkup(msg.bot.ctx, msg, kb)
+}
+
+func (msg Message) Delete() error {
+	if msg.bot == nil {
+		return errUnboundMessage
+	}
+	return msg.bot.deleteMessage(msg.bot.ctx, msg)
+}
+
+func (msg Message) Pin(opts ...SendOption) error {
+	if msg.bot == nil {
+		return errUnboundMessage
+	}
+	return msg.bot.pinMessage(msg.bot.ctx, msg, opts)
+}
+
+func (msg Message) Unpin() error {
+	if msg.bot == nil {
+		return errUnboundMessage
+	}
+	return msg.bot.unpinMessage(msg.bot.ctx, msg)
+}
+
+func (msg Message) EditLiveLocation(latitude, longitude float64) (Message, error) {
+	if msg.bot == nil {
+		return Message{}, errUnboundMessage
+	}
+	return msg.bot.editLiveLocation(msg.bot.ctx, msg, latitude, longitude)
+}
+
+func (msg Message) StopLiveLocation() (Message, error) {
+	if msg.bot == nil {
+		return Message{}, errUnboundMessage
+	}
+	return msg.bot.stopLiveLocation(msg.bot.ctx, msg)
+}
+
+func (bot *Bot) editMessage(ctx context.Context, msg Message, text string, opts []SendOption) (Message, error) {
+	so := newSendOptions(0, opts)
+	params := msg.toParams()
+	params["text"] = text
+	params.AddNonEmpty("parse_mode", string(so.parseMode))
+	params.AddBool("disable_web_page_preview", so.noLinkPreview)
+	if err := addInlineKeyboard(params, so.keyboard); err != nil {
+		return Message{}, err
+	}
+	return bot.requestMessage(ctx, msg.ChatID, "editMessageText", params)
+}
+
+func (bot *Bot) editCaption(ctx context.Context, msg Message, caption string, opts []SendOption) (Message, error) {
+	so := newSendOptions(0, opts)
+	params := msg.toParams()
+	params["caption"] = caption
+	params.AddNonEmpty("parse_mode", string(so.parseMode))
+	if err := addInlineKeyboard(params, so.keyboard); err != nil {
+		return Message{}, err
+	}
+	return bot.requestMessage(ctx, msg.ChatID, "editMessageCaption", params)
+}
+
+// editReplyMarkup removes the keyboard of the message if kb is nil
+func (bot *Bot) editReplyMarkup(ctx context.Context, msg Message, kb Keyboard) (Message, error) {
+	params := msg.toParams()
+	if err := addInlineKeyboard(params, kb); err != nil {
+		return Message{}, err
+	}
+	return bot.requestMessage(ctx, msg.ChatID, "editMessageReplyMarkup", params)
+}
+
+func (bot *Bot) deleteMessage(ctx context.Context, msg Message) error {
+	return bot.request(ctx, msg.ChatID, true, func() error {
+		_, err := bot.api.Request(tgbotapi.NewDeleteMessage(msg.ChatID, msg.MessageID))
+		return err
+	})
+}
+
+func (bot *Bot) pinMessage(ctx context.Context, msg Message, opts []SendOption) error {
+	so := newSendOptions(0, opts)
+	pin := tgbotapi.PinChatMessageConfig{
+		ChatID:              msg.ChatID,
+		MessageID:           msg.MessageID,
+		DisableNotification: so.silent,
+	}
+	return bot.request(ctx, msg.ChatID, true, func() error {
+		_, err := bot.api.Request(pin)
+		return err
+	})
+}
+
+func (bot *Bot) unpinMessage(ctx context.Context, msg Message) error {
+	unpin := tgbotapi.UnpinChatMessageConfig{
+		ChatID:    msg.ChatID,
+		MessageID: msg.MessageID,
+	}
+	return bot.request(ctx, msg.ChatID, true, func() error {
+		_, err := bot.api.Request(unpin)
+		return err
+	})
+}
+
+func (bot *Bot) forwardMessage(ctx context.Context, chatID int64, msg Message, opts []SendOption) (Message, error) {
+	so := newSendOptions(0, opts)
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero64("from_chat_id", msg.ChatID)
+	params.AddNonZero("message_id", msg.MessageID)
+	params.AddNonZero("message_thread_id", so.threadID)
+	params.AddBool("disable_notification", so.silent)
+	params.AddBool("protect_content", so.protectContent)
+	return bot.requestMessage(ctx, chatID, "forwardMessage", params)
+}
+
+func (bot *Bot) copyMessage(ctx context.Context, chatID int64, msg Message, opts []SendOption) (Message, error) {
+	so := newSendOptions(0, opts)
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero64("from_chat_id", msg.ChatID)
+	params.AddNonZero("message_id", msg.MessageID)
+	if err := so.addParams(params); err != nil {
+		return Message{}, err
+	}
+
+	// copyMessage only returns the ID of the new message
+	var copied tgbotapi.MessageID
+	err := bot.request(ctx, chatID, true, func() error {
+		resp, err := bot.api.MakeRequest("copyMessage", params)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &copied)
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	return newMessage(bot, chatID, copied.MessageID), nil
+}
+
+func (msg Message) toParams() tgbotapi.Params {
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", msg.ChatID)
+	params.AddNonZero("message_id", msg.MessageID)
+	return params
+}
+
+func addInlineKeyboard(params tgbotapi.Params, kb Keyboard) error {
+	if kb == nil {
+		return nil
+	}
+	if _, ok := kb.(*InlineKeyboard); !ok {
+		return fmt.Errorf("only inline keyboards can be attached to edited messages")
+	}
+	return params.AddInterface("reply_markup", kb.toReplyMarkup())
+}
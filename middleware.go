@@ -0,0 +1,13 @@
+package botkit
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type Handler func(ctx *Context, update tgbotapi.Update) error
+
+type MessageHandler func(ctx *Context, text string) error
+
+// Middleware wraps a Handler; returning without calling next stops the update from being handled.
+// Updates without a chat (inline queries, chosen inline results, payments, poll answers) get the context of the sending user
+type Middleware func(next Handler) Handler
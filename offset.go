@@ -0,0 +1,61 @@
+package botkit
+
+import (
+	"sync"
+)
+
+type offsetTracker struct {
+	mu        sync.Mutex
+	pending   map[int]struct{}
+	last      int
+	committed int
+}
+
+func newOffsetTracker(committed int) *offsetTracker {
+	return &offsetTracker{
+		pending:   make(map[int]struct{}),
+		last:      committed,
+		committed: committed,
+	}
+}
+
+func (t *offsetTracker) begin(updateID int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if updateID <= t.committed {
+		return false
+	}
+	if _, ok := t.pending[updateID]; ok {
+		return false
+	}
+	t.pending[updateID] = struct{}{}
+	if updateID > t.last {
+		t.last = updateID
+	}
+	return true
+}
+
+// done returns the highest update ID up to which every update has been processed
+// and whether it has advanced since the last call
+func (t *offsetTracker) done(updateID int) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pending, updateID)
+	committed := t.last
+	for id := range t.pending {
+		if id <= committed {
+			committed = id - 1
+		}
+	}
+	if committed <= t.committed {
+		return t.committed, false
+	}
+	t.committed = committed
+	return committed, true
+}
+
+func (t *offsetTracker) lastCommitted() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.committed
+}
@@ -0,0 +1,96 @@
+package botkit
+
+import (
+	"testing"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	type step struct {
+		begin     int
+		done      int
+		ok        bool
+		committed int
+	}
+	tests := []struct {
+		name      string
+		committed int
+		steps     []step
+	}{
+		{
+			name: "in order",
+			steps: []step{
+				{begin: 1, ok: true},
+				{done: 1, ok: true, committed: 1},
+				{begin: 2, ok: true, committed: 1},
+				{done: 2, ok: true, committed: 2},
+			},
+		},
+		{
+			name: "out of order completion",
+			steps: []step{
+				{begin: 1, ok: true},
+				{begin: 2, ok: true},
+				{begin: 3, ok: true},
+				{done: 3, committed: 0},
+				{done: 2, committed: 0},
+				{done: 1, ok: true, committed: 3},
+			},
+		},
+		{
+			name: "only past the lowest in-flight update",
+			steps: []step{
+				{begin: 1, ok: true},
+				{begin: 2, ok: true},
+				{begin: 3, ok: true},
+				{done: 1, ok: true, committed: 1},
+				{done: 3, committed: 1},
+				{begin: 4, ok: true, committed: 1},
+				{done: 4, committed: 1},
+				{done: 2, ok: true, committed: 4},
+			},
+		},
+		{
+			name:      "duplicates and already committed updates",
+			committed: 10,
+			steps: []step{
+				{begin: 9, committed: 10},
+				{begin: 10, committed: 10},
+				{begin: 11, ok: true, committed: 10},
+				{begin: 11, committed: 10},
+				{done: 11, ok: true, committed: 11},
+				{begin: 11, committed: 11},
+			},
+		},
+		{
+			name:      "gaps in update IDs",
+			committed: 5,
+			steps: []step{
+				{begin: 8, ok: true},
+				{begin: 20, ok: true},
+				{done: 20, ok: true, committed: 7},
+				{done: 8, ok: true, committed: 20},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tracker := newOffsetTracker(tt.committed)
+		for i, s := range tt.steps {
+			var ok bool
+			if s.begin > 0 {
+				ok = tracker.begin(s.begin)
+			} else {
+				_, ok = tracker.done(s.done)
+			}
+			if ok != s.ok {
+				t.Errorf("%s: step %d returned %v, want %v", tt.name, i, ok, s.ok)
+			}
+			want := s.committed
+			if want == 0 {
+				want = tt.committed
+			}
+			if committed := tracker.lastCommitted(); committed != want {
+				t.Errorf("%s: step %d committed %d, want %d", tt.name, i, committed, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package botkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type ShippingQueryHandler func(ctx *Context, q *ShippingQuery) error
+
+type PreCheckoutQueryHandler func(ctx *Context, q *PreCheckoutQuery) error
+
+type SuccessfulPaymentHandler func(ctx *Context, p *Payment) error
+
+type Invoice struct {
+	Title               string  `json:"title"`
+	Description         string  `json:"description"`
+	Payload             string  `json:"payload"`
+	ProviderToken       string  `json:"-"`
+	Currency            string  `json:"currency"`
+	Prices              []Price `json:"prices"`
+	PhotoURL            string  `json:"photo_url,omitempty"`
+	MaxTipAmount        int     `json:"max_tip_amount,omitempty"`
+	SuggestedTipAmounts []int   `json:"suggested_tip_amounts,omitempty"`
+	NeedName            bool    `json:"need_name,omitempty"`
+	NeedPhoneNumber     bool    `json:"need_phone_number,omitempty"`
+	NeedEmail           bool    `json:"need_email,omitempty"`
+	NeedShippingAddress bool    `json:"need_shipping_address,omitempty"`
+	IsFlexible          bool    `json:"is_flexible,omitempty"`
+}
+
+type Price struct {
+	Label  string `json:"label"`
+	Amount int    `json:"amount"`
+}
+
+type ShippingOption struct {
+	ID     string
+	Title  string
+	Prices []Price
+}
+
+type ShippingQuery struct {
+	bot            *Bot
+	id             string
+	answered       bool
+	InvoicePayload string
+	Address        tgbotapi.ShippingAddress
+}
+
+type PreCheckoutQuery struct {
+	bot              *Bot
+	id               string
+	answered         bool
+	Currency         string
+	TotalAmount      int
+	InvoicePayload   string
+	ShippingOptionID string
+	OrderInfo        *tgbotapi.OrderInfo
+}
+
+type Payment struct {
+	Currency         string              `json:"currency"`
+	TotalAmount      int                 `json:"total_amount"`
+	InvoicePayload   string              `json:"invoice_payload"`
+	ShippingOptionID string              `json:"shipping_option_id,omitempty"`
+	OrderInfo        *tgbotapi.OrderInfo `json:"order_info,omitempty"`
+	TelegramChargeID string              `json:"telegram_charge_id"`
+	ProviderChargeID string              `json:"provider_charge_id"`
+}
+
+// NewInvoice creates an invoice that uses the bot's payment provider token unless ProviderToken is set
+func NewInvoice(title, description, payload, currency string, prices ...Price) *Invoice {
+	return &Invoice{
+		Title:       title,
+		Description: description,
+		Payload:     payload,
+		Currency:    currency,
+		Prices:      prices,
+	}
+}
+
+func NewPrice(label string, amount int) Price {
+	return Price{
+		Label:  label,
+		Amount: amount,
+	}
+}
+
+func (inv *Invoice) toChattable(chatID int64, replyID int) tgbotapi.InvoiceConfig {
+	prices := make([]tgbotapi.LabeledPrice, len(inv.Prices))
+	for i, price := range inv.Prices {
+		prices[i] = tgbotapi.LabeledPrice(price)
+	}
+	msg := tgbotapi.NewInvoice(chatID, inv.Title, inv.Description, inv.Payload, inv.ProviderToken, "", inv.Currency, prices)
+	msg.ReplyToMessageID = replyID
+	msg.PhotoURL = inv.PhotoURL
+	msg.MaxTipAmount = inv.MaxTipAmount
+	msg.SuggestedTipAmounts = inv.SuggestedTipAmounts
+	msg.NeedName = inv.NeedName
+	msg.NeedPhoneNumber = inv.NeedPhoneNumber
+	msg.NeedEmail = inv.NeedEmail
+	msg.NeedShippingAddress = inv.NeedShippingAddress
+	msg.IsFlexible = inv.IsFlexible
+	return msg
+}
+
+func (q *ShippingQuery) Approve(options ...ShippingOption) error {
+	shippingOptions := make([]tgbotapi.ShippingOption, len(options))
+	for i, opt := range options {
+		prices := make([]tgbotapi.LabeledPrice, len(opt.Prices))
+		for j, price := range opt.Prices {
+			prices[j] = tgbotapi.LabeledPrice(price)
+		}
+		shippingOptions[i] = tgbotapi.ShippingOption{
+			ID:     opt.ID,
+			Title:  opt.Title,
+			Prices: prices,
+		}
+	}
+	return q.answer(tgbotapi.ShippingConfig{
+		ShippingQueryID: q.id,
+		OK:              true,
+		ShippingOptions: shippingOptions,
+	})
+}
+
+func (q *ShippingQuery) Reject(errMsg string) error {
+	return q.answer(tgbotapi.ShippingConfig{
+		ShippingQueryID: q.id,
+		ErrorMessage:    errMsg,
+	})
+}
+
+func (q *ShippingQuery) answer(cfg tgbotapi.ShippingConfig) error {
+	if q.answered {
+		return fmt.Errorf("shipping query already answered")
+	}
+	q.answered = true
+	_, err := q.bot.api.Request(cfg)
+	return err
+}
+
+func (q *PreCheckoutQuery) Approve() error {
+	return q.answer(tgbotapi.PreCheckoutConfig{
+		PreCheckoutQueryID: q.id,
+		OK:                 true,
+	})
+}
+
+func (q *PreCheckoutQuery) Reject(errMsg string) error {
+	return q.answer(tgbotapi.PreCheckoutConfig{
+		PreCheckoutQueryID: q.id,
+		ErrorMessage:       errMsg,
+	})
+}
+
+func (q *PreCheckoutQuery) answer(cfg tgbotapi.PreCheckoutConfig) error {
+	if q.answered {
+		return fmt.Errorf("pre-checkout query already answered")
+	}
+	q.answered = true
+	_, err := q.bot.api.Request(cfg)
+	return err
+}
+
+func newPayment(p *tgbotapi.SuccessfulPayment) *Payment {
+	return &Payment{
+		Currency:         p.Currency,
+		TotalAmount:      p.TotalAmount,
+		InvoicePayload:   p.InvoicePayload,
+		ShippingOptionID: p.ShippingOptionID,
+		OrderInfo:        p.OrderInfo,
+		TelegramChargeID: p.TelegramPaymentChargeID,
+		ProviderChargeID: p.ProviderPaymentChargeID,
+	}
+}
+
+func (bot *Bot) sendInvoice(ctx context.Context, chatID int64, inv *Invoice, replyID int) (Message, error) {
+	msg := inv.toChattable(chatID, replyID)
+	if len(msg.ProviderToken) == 0 {
+		msg.ProviderToken = bot.paymentProviderToken
+	}
+	return bot.sendChattable(ctx, chatID, msg, true)
+}
+
+// queries left unanswered by the handler are answered here, as telegram expects an answer within 10 seconds
+func (bot *Bot) handleShippingQuery(ctx *Context, sq *tgbotapi.ShippingQuery) error {
+	q := &ShippingQuery{
+		bot:            bot,
+		id:             sq.ID,
+		InvoicePayload: sq.InvoicePayload,
+	}
+	if sq.ShippingAddress != nil {
+		q.Address = *sq.ShippingAddress
+	}
+	var err error
+	if bot.shippingQueryHandler != nil {
+		err = bot.shippingQueryHandler(ctx, q)
+	}
+	if !q.answered {
+		if rejectErr := q.Reject("Shipping is not available"); rejectErr != nil {
+			bot.logger.Error("failed to answer shipping query", slogContext(ctx), slog.Any("err", rejectErr))
+		}
+	}
+	return err
+}
+
+func (bot *Bot) handlePreCheckoutQuery(ctx *Context, pq *tgbotapi.PreCheckoutQuery) error {
+	q := &PreCheckoutQuery{
+		bot:              bot,
+		id:               pq.ID,
+		Currency:         pq.Currency,
+		TotalAmount:      pq.TotalAmount,
+		InvoicePayload:   pq.InvoicePayload,
+		ShippingOptionID: pq.ShippingOptionID,
+		OrderInfo:        pq.OrderInfo,
+	}
+	var err error
+	if bot.preCheckoutQueryHandler != nil {
+		err = bot.preCheckoutQueryHandler(ctx, q)
+	}
+	if !q.answered {
+		var answerErr error
+		if err != nil {
+			answerErr = q.Reject("The payment could not be processed")
+		} else {
+			answerErr = q.Approve()
+		}
+		if answerErr != nil {
+			bot.logger.Error("failed to answer pre-checkout query", slogContext(ctx), slog.Any("err", answerErr))
+		}
+	}
+	return err
+}
+
+func (bot *Bot) handlePayment(ctx *Context, msg *tgbotapi.Message) error {
+	payment := newPayment(msg.SuccessfulPayment)
+	if dlg := bot.getDialog(msg.From.ID, msg.Chat.ID); dlg != nil {
+		if q := dlg.LastQuery(); q != nil && q.Kind == InvoiceQueryKind && q.Invoice.Payload == payment.InvoicePayload {
+			data, err := json.Marshal(payment)
+			if err != nil {
+				return err
+			}
+			bot.handleDialogInput(ctx, dlg, dialogInputPayment, string(data))
+		}
+	}
+	// the payment hook runs even if a dialog consumed the payment, so no payment goes unrecorded
+	if bot.successfulPaymentHandler != nil {
+		if err := bot.successfulPaymentHandler(ctx, payment); err != nil {
+			return fmt.Errorf("successful payment handler: %v", err)
+		}
+	}
+	return nil
+}
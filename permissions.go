@@ -0,0 +1,98 @@
+package botkit
+
+import (
+	"fmt"
+	"slices"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	AdminOnlyPermission Permission = iota
+	PrivateOnlyPermission
+	GroupOnlyPermission
+	AllowlistPermission
+)
+
+type Permission int
+
+type PermissionError struct {
+	Cmd        string
+	Permission Permission
+	Err        error
+}
+
+type commandPermissions struct {
+	adminOnly    bool
+	privateOnly  bool
+	groupOnly    bool
+	allowedUsers []int64
+}
+
+func (p Permission) String() string {
+	switch p {
+	case AdminOnlyPermission:
+		return "admin only"
+	case PrivateOnlyPermission:
+		return "private only"
+	case GroupOnlyPermission:
+		return "group only"
+	case AllowlistPermission:
+		return "allowed users only"
+	default:
+		return fmt.Sprintf("permission(%d)", int(p))
+	}
+}
+
+func (err *PermissionError) Error() string {
+	if err.Err != nil {
+		return fmt.Sprintf("%s: permission denied (%v): %v", err.Cmd, err.Permission, err.Err)
+	}
+	return fmt.Sprintf("%s: permission denied (%v)", err.Cmd, err.Permission)
+}
+
+func (err *PermissionError) Unwrap() error {
+	return err.Err
+}
+
+func (bot *Bot) checkPermissions(ctx *Context, c *command) error {
+	perms := &c.permissions
+	if perms.privateOnly && !ctx.isPrivate {
+		return &PermissionError{Cmd: c.name, Permission: PrivateOnlyPermission}
+	}
+	if perms.groupOnly && ctx.isPrivate {
+		return &PermissionError{Cmd: c.name, Permission: GroupOnlyPermission}
+	}
+	if len(perms.allowedUsers) > 0 && !slices.Contains(perms.allowedUsers, ctx.userID) {
+		return &PermissionError{Cmd: c.name, Permission: AllowlistPermission}
+	}
+	if perms.adminOnly && !ctx.isPrivate {
+		isAdmin, err := bot.isSenderAdmin(ctx)
+		if err != nil || !isAdmin {
+			return &PermissionError{Cmd: c.name, Permission: AdminOnlyPermission, Err: err}
+		}
+	}
+	return nil
+}
+
+// isSenderAdmin treats messages sent on behalf of the chat itself (anonymous admins, channel posts) as sent by an admin,
+// while other sender chats (like linked channels) are not members that could be looked up
+func (bot *Bot) isSenderAdmin(ctx *Context) (bool, error) {
+	if ctx.senderChatID != 0 {
+		return ctx.senderChatID == ctx.chatID, nil
+	}
+	return bot.isChatAdmin(ctx.chatID, ctx.userID)
+}
+
+func (bot *Bot) isChatAdmin(chatID, userID int64) (bool, error) {
+	member, err := bot.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: chatID,
+			UserID: userID,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return member.IsAdministrator() || member.IsCreator(), nil
+}
@@ -1,11 +1,11 @@
 package botkit
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/razzie/botkit/format"
 )
 
 const (
@@ -14,6 +14,7 @@ const (
 	MultiChoiceQueryKind
 	FileInputQueryKind
 	RetryQueryKind
+	InvoiceQueryKind
 )
 
 var RetryQuery = &Query{Kind: RetryQueryKind}
@@ -21,11 +22,13 @@ var RetryQuery = &Query{Kind: RetryQueryKind}
 type QueryKind int
 
 type Query struct {
-	Name      string    `json:"name"`
-	Kind      QueryKind `json:"kind"`
-	Text      string    `json:"text"`
-	Choices   []string  `json:"choices,omitempty"`
-	MessageID int       `json:"message_id,omitempty"`
+	Name          string    `json:"name"`
+	Kind          QueryKind `json:"kind"`
+	Text          string    `json:"text"`
+	Choices       []string  `json:"choices,omitempty"`
+	MessageID     int       `json:"message_id,omitempty"`
+	Invoice       *Invoice  `json:"invoice,omitempty"`
+	FormattedText string    `json:"formatted_text,omitempty"`
 }
 
 func NewTextInputQuery(name, text string) *Query {
@@ -62,6 +65,20 @@ func NewFileInputQuery(name, text string) *Query {
 	}
 }
 
+func NewInvoiceQuery(name string, invoice *Invoice) *Query {
+	return &Query{
+		Name:    name,
+		Kind:    InvoiceQueryKind,
+		Invoice: invoice,
+	}
+}
+
+// WithFormat replaces the plain query text with formatted text
+func (q *Query) WithFormat(text format.Text) *Query {
+	q.FormattedText = text.Render(format.MarkdownV2)
+	return q
+}
+
 func (qk QueryKind) HasTextResponse() bool {
 	switch qk {
 	case TextInputQueryKind, FileInputQueryKind:
@@ -81,6 +98,9 @@ func (qk QueryKind) HasChoiceResponse() bool {
 }
 
 func (q *Query) toChattable(dlg *Dialog) tgbotapi.Chattable {
+	if q.Kind == InvoiceQueryKind {
+		return q.Invoice.toChattable(dlg.chatID, 0)
+	}
 	msgText := q.getMessageText(dlg)
 	msg := tgbotapi.NewMessage(dlg.chatID, msgText)
 	msg.ParseMode = tgbotapi.ModeMarkdownV2
@@ -88,14 +108,22 @@ func (q *Query) toChattable(dlg *Dialog) tgbotapi.Chattable {
 	return &msg
 }
 
+func (q *Query) clone() *Query {
+	clone := *q
+	return &clone
+}
+
 func (q *Query) setMessageID(messageID int) {
 	q.MessageID = messageID
 }
 
 func (q *Query) getMessageText(dlg *Dialog) string {
-	msgText := q.Text
+	msgText := q.FormattedText
+	if len(msgText) == 0 {
+		msgText = format.Escape(format.MarkdownV2, q.Text)
+	}
 	if !dlg.isPrivate() {
-		msgText = fmt.Sprintf("[%s](tg://user?id=%d) %s", dlg.data.Username, dlg.userID, msgText)
+		msgText = format.Mention(dlg.data.Username, dlg.userID).Render(format.MarkdownV2) + " " + msgText
 	}
 	return msgText
 }
@@ -0,0 +1,163 @@
+package botkit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/razzie/razcache"
+)
+
+const (
+	PerUser RateLimitScope = iota
+	PerChat
+)
+
+type RateLimitScope int
+
+type RateLimit struct {
+	Rate  int
+	Per   time.Duration
+	Burst int
+	Scope RateLimitScope
+}
+
+type RateLimitError struct {
+	Cmd        string
+	RetryAfter time.Duration
+	repeated   bool
+}
+
+func PerUserRateLimit(rate int, per time.Duration) RateLimit {
+	return RateLimit{
+		Rate:  rate,
+		Per:   per,
+		Burst: rate,
+		Scope: PerUser,
+	}
+}
+
+func PerChatRateLimit(rate int, per time.Duration) RateLimit {
+	return RateLimit{
+		Rate:  rate,
+		Per:   per,
+		Burst: rate,
+		Scope: PerChat,
+	}
+}
+
+func (err *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: rate limit exceeded, retry after %v", err.Cmd, err.RetryAfter)
+}
+
+type rateLimitBucket struct {
+	key   string
+	cmd   string
+	limit RateLimit
+}
+
+// checkRateLimits checks the global and command limits together and only takes tokens if every limit allows the call
+func (bot *Bot) checkRateLimits(ctx *Context, c *command) error {
+	buckets := append(getRateLimitBuckets(ctx, "*", bot.rateLimits), getRateLimitBuckets(ctx, c.name, c.rateLimits)...)
+
+	bot.rateLimitMu.Lock()
+	defer bot.rateLimitMu.Unlock()
+
+	now := time.Now()
+	tokens := make([]float64, len(buckets))
+	skip := make([]bool, len(buckets))
+	for i, b := range buckets {
+		var retryAfter time.Duration
+		var err error
+		tokens[i], retryAfter, err = bot.getTokens(b, now)
+		if err != nil {
+			bot.logger.Error("rate limiter error", slog.String("key", b.key), slog.Any("err", err))
+			skip[i] = true
+			continue
+		}
+		if retryAfter > 0 {
+			return bot.newRateLimitError(ctx, b.key, b.cmd, retryAfter)
+		}
+	}
+	for i, b := range buckets {
+		if skip[i] {
+			continue
+		}
+		if err := bot.saveTokens(b, tokens[i]-1, now); err != nil {
+			bot.logger.Error("rate limiter error", slog.String("key", b.key), slog.Any("err", err))
+		}
+	}
+	return nil
+}
+
+func getRateLimitBuckets(ctx *Context, cmd string, limits []RateLimit) []rateLimitBucket {
+	var buckets []rateLimitBucket
+	for i, limit := range limits {
+		if limit.Rate <= 0 || limit.Per <= 0 {
+			continue
+		}
+		var key string
+		switch limit.Scope {
+		case PerChat:
+			key = fmt.Sprintf("ratelimit:%s:%d:chat:%d", cmd, i, ctx.chatID)
+		default:
+			key = fmt.Sprintf("ratelimit:%s:%d:user:%d", cmd, i, ctx.userID)
+		}
+		buckets = append(buckets, rateLimitBucket{key: key, cmd: cmd, limit: limit})
+	}
+	return buckets
+}
+
+// getTokens implements a token bucket on top of the cache and tells how long to wait if there are no tokens left.
+// It's not atomic, so replicas sharing a cache might let a few extra commands through in a race.
+func (bot *Bot) getTokens(b rateLimitBucket, now time.Time) (float64, time.Duration, error) {
+	burst := float64(max(b.limit.Burst, 1))
+	rate := float64(b.limit.Rate) / float64(b.limit.Per)
+	tokens := burst
+
+	value, err := bot.cache.Get(b.key)
+	if err != nil && err != razcache.ErrNotFound {
+		return 0, 0, err
+	}
+	if err == nil {
+		var last int64
+		if _, err := fmt.Sscanf(value, "%g:%d", &tokens, &last); err != nil {
+			return 0, 0, fmt.Errorf("invalid token bucket %q", value)
+		}
+		tokens = math.Min(burst, tokens+float64(now.UnixNano()-last)*rate)
+	}
+
+	if tokens < 1 {
+		return tokens, time.Duration((1 - tokens) / rate), nil
+	}
+	return tokens, 0, nil
+}
+
+func (bot *Bot) saveTokens(b rateLimitBucket, tokens float64, now time.Time) error {
+	burst := float64(max(b.limit.Burst, 1))
+	rate := float64(b.limit.Rate) / float64(b.limit.Per)
+	ttl := time.Duration(burst / rate)
+	return bot.cache.Set(b.key, fmt.Sprintf("%g:%d", tokens, now.UnixNano()), ttl)
+}
+
+func (bot *Bot) newRateLimitError(ctx *Context, key, cmd string, retryAfter time.Duration) error {
+	err := &RateLimitError{
+		Cmd:        cmd,
+		RetryAfter: retryAfter.Round(time.Second),
+	}
+	if err.RetryAfter < time.Second {
+		err.RetryAfter = time.Second
+	}
+	// only notify the user once per cooldown
+	notifiedKey := key + ":notified"
+	if _, getErr := bot.cache.Get(notifiedKey); getErr == nil {
+		err.repeated = true
+		return err
+	}
+	if setErr := bot.cache.Set(notifiedKey, "1", retryAfter); setErr != nil {
+		bot.logger.Error("failed to save rate limit notification", slog.String("key", notifiedKey), slog.Any("err", setErr))
+	}
+	bot.logger.Warn("rate limit exceeded", slogContext(ctx), slog.String("cmd", cmd), slog.Duration("retryAfter", retryAfter))
+	return err
+}
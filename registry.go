@@ -0,0 +1,183 @@
+package botkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/razzie/razcache"
+)
+
+const chatRegistryUpdateInterval = time.Minute
+
+type ChatInfo struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	IsKicked  bool      `json:"is_kicked"`
+}
+
+func (bot *Bot) Chats() ([]ChatInfo, error) {
+	chatIDs, err := bot.getChatIndex()
+	if err != nil {
+		return nil, err
+	}
+	chats := make([]ChatInfo, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		info, err := bot.getChatInfo(chatID)
+		if err == razcache.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, *info)
+	}
+	return chats, nil
+}
+
+func (bot *Bot) GetChatInfo(chatID int64) (ChatInfo, error) {
+	info, err := bot.getChatInfo(chatID)
+	if err != nil {
+		return ChatInfo{}, err
+	}
+	return *info, nil
+}
+
+func (bot *Bot) trackChat(update tgbotapi.Update) {
+	if !bot.chatRegistry {
+		return
+	}
+	switch {
+	case update.MyChatMember != nil:
+		member := update.MyChatMember.NewChatMember
+		isKicked := member.WasKicked() || member.HasLeft()
+		bot.recordChat(&update.MyChatMember.Chat, &isKicked)
+	case update.Message != nil:
+		bot.recordChat(update.Message.Chat, nil)
+	case update.ChannelPost != nil:
+		bot.recordChat(update.ChannelPost.Chat, nil)
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		bot.recordChat(update.CallbackQuery.Message.Chat, nil)
+	}
+}
+
+func (bot *Bot) recordChat(chat *tgbotapi.Chat, isKicked *bool) {
+	now := time.Now()
+	bot.registryMu.Lock()
+	defer bot.registryMu.Unlock()
+
+	// entries are only needed for an update interval, so the map doesn't grow with every chat ever seen
+	if now.Sub(bot.registrySwept) >= chatRegistryUpdateInterval {
+		for id, lastSeen := range bot.recentChats {
+			if now.Sub(lastSeen) >= chatRegistryUpdateInterval {
+				delete(bot.recentChats, id)
+			}
+		}
+		bot.registrySwept = now
+	}
+
+	if isKicked == nil && now.Sub(bot.recentChats[chat.ID]) < chatRegistryUpdateInterval {
+		return
+	}
+
+	info, err := bot.getChatInfo(chat.ID)
+	if err == razcache.ErrNotFound {
+		info = &ChatInfo{ID: chat.ID, FirstSeen: now}
+		if err := bot.addToChatIndex(chat.ID); err != nil {
+			bot.logger.Error("failed to update chat index", slog.Int64("chatID", chat.ID), slog.Any("err", err))
+		}
+	} else if err != nil {
+		bot.logger.Error("failed to load chat info", slog.Int64("chatID", chat.ID), slog.Any("err", err))
+		return
+	}
+
+	info.Type = chat.Type
+	info.Title = chat.Title
+	info.Username = chat.UserName
+	info.LastSeen = now
+	info.IsKicked = isKicked != nil && *isKicked
+	if err := bot.saveChatInfo(info); err != nil {
+		bot.logger.Error("failed to save chat info", slog.Int64("chatID", chat.ID), slog.Any("err", err))
+		return
+	}
+	bot.recentChats[chat.ID] = now
+}
+
+func (bot *Bot) markChatKicked(chatID int64) {
+	if !bot.chatRegistry {
+		return
+	}
+	bot.registryMu.Lock()
+	defer bot.registryMu.Unlock()
+
+	info, err := bot.getChatInfo(chatID)
+	if err != nil {
+		return
+	}
+	info.IsKicked = true
+	if err := bot.saveChatInfo(info); err != nil {
+		bot.logger.Error("failed to save chat info", slog.Int64("chatID", chatID), slog.Any("err", err))
+	}
+}
+
+func (bot *Bot) getChatInfo(chatID int64) (*ChatInfo, error) {
+	infoJson, err := bot.cache.Get(fmt.Sprintf("chatinfo:%d", chatID))
+	if err != nil {
+		return nil, err
+	}
+	info := new(ChatInfo)
+	if err := json.Unmarshal([]byte(infoJson), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (bot *Bot) saveChatInfo(info *ChatInfo) error {
+	infoJson, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return bot.cache.Set(fmt.Sprintf("chatinfo:%d", info.ID), string(infoJson), 0)
+}
+
+func (bot *Bot) getChatIndex() ([]int64, error) {
+	index, err := bot.cache.Get("chatindex")
+	if err == razcache.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var chatIDs []int64
+	for _, id := range strings.Split(index, ",") {
+		if chatID, err := strconv.ParseInt(id, 10, 64); err == nil {
+			chatIDs = append(chatIDs, chatID)
+		}
+	}
+	return chatIDs, nil
+}
+
+// addToChatIndex is a read-modify-write, so replicas sharing a cache could race here
+func (bot *Bot) addToChatIndex(chatID int64) error {
+	chatIDs, err := bot.getChatIndex()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(chatIDs, chatID) {
+		return nil
+	}
+	ids := make([]string, 0, len(chatIDs)+1)
+	for _, id := range append(chatIDs, chatID) {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return bot.cache.Set("chatindex", strings.Join(ids, ","), 0)
+}
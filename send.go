@@ -0,0 +1,114 @@
+package botkit
+
+import (
+	"context"
+	"encoding/json"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/razzie/botkit/format"
+)
+
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	replyID        int
+	threadID       int
+	silent         bool
+	protectContent bool
+	noLinkPreview  bool
+	parseMode      format.ParseMode
+	keyboard       Keyboard
+}
+
+func Silent() SendOption {
+	return func(so *sendOptions) {
+		so.silent = true
+	}
+}
+
+func ProtectContent() SendOption {
+	return func(so *sendOptions) {
+		so.protectContent = true
+	}
+}
+
+func NoLinkPreview() SendOption {
+	return func(so *sendOptions) {
+		so.noLinkPreview = true
+	}
+}
+
+func InThread(threadID int) SendOption {
+	return func(so *sendOptions) {
+		so.threadID = threadID
+	}
+}
+
+func ReplyTo(messageID int) SendOption {
+	return func(so *sendOptions) {
+		so.replyID = messageID
+	}
+}
+
+// WithParseMode sends the text as markup in the given parse mode instead of plain text.
+// The text is sent as is, so it has to be escaped already.
+func WithParseMode(mode format.ParseMode) SendOption {
+	return func(so *sendOptions) {
+		so.parseMode = mode
+	}
+}
+
+func WithKeyboard(kb Keyboard) SendOption {
+	return func(so *sendOptions) {
+		so.keyboard = kb
+	}
+}
+
+func newSendOptions(replyID int, opts []SendOption) *sendOptions {
+	so := &sendOptions{
+		replyID: replyID,
+	}
+	for _, opt := range opts {
+		opt(so)
+	}
+	return so
+}
+
+func (so *sendOptions) addParams(params tgbotapi.Params) error {
+	params.AddNonZero("reply_to_message_id", so.replyID)
+	params.AddNonZero("message_thread_id", so.threadID)
+	params.AddBool("disable_notification", so.silent)
+	params.AddBool("protect_content", so.protectContent)
+	if so.keyboard != nil {
+		return params.AddInterface("reply_markup", so.keyboard.toReplyMarkup())
+	}
+	return nil
+}
+
+func (bot *Bot) sendText(ctx context.Context, chatID int64, text string, replyID int, opts []SendOption) (Message, error) {
+	so := newSendOptions(replyID, opts)
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", chatID)
+	params["text"] = text
+	params.AddNonEmpty("parse_mode", string(so.parseMode))
+	params.AddBool("disable_web_page_preview", so.noLinkPreview)
+	if err := so.addParams(params); err != nil {
+		return Message{}, err
+	}
+	return bot.requestMessage(ctx, chatID, "sendMessage", params)
+}
+
+func (bot *Bot) requestMessage(ctx context.Context, chatID int64, method string, params tgbotapi.Params) (Message, error) {
+	var msg tgbotapi.Message
+	err := bot.request(ctx, chatID, true, func() error {
+		resp, err := bot.api.MakeRequest(method, params)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &msg)
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	return newSentMessage(bot, &msg), nil
+}
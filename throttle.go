@@ -0,0 +1,233 @@
+package botkit
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const throttlerSweepInterval = 1000
+
+var (
+	defaultGlobalSendRateLimit = RateLimit{Rate: 30, Per: time.Second, Burst: 30}
+	defaultChatSendRateLimit   = RateLimit{Rate: 1, Per: time.Second, Burst: 3}
+	defaultGroupSendRateLimit  = RateLimit{Rate: 20, Per: time.Minute, Burst: 3}
+)
+
+type sendThrottler struct {
+	mu           sync.Mutex
+	global       *gcra
+	chats        map[int64]*gcra
+	chatLimit    RateLimit
+	groupLimit   RateLimit
+	reservations int
+}
+
+// gcra is an in-memory rate limiter that tells how long to wait before the next event
+type gcra struct {
+	interval  time.Duration
+	tolerance time.Duration
+	tat       time.Time
+}
+
+// sendQueue runs the requests of each chat in order on a goroutine of their own,
+// so waiting for the rate limits of a chat doesn't hold up anything else
+type sendQueue struct {
+	mu    sync.Mutex
+	chats map[int64][]func()
+}
+
+func newSendQueue() *sendQueue {
+	return &sendQueue{
+		chats: make(map[int64][]func()),
+	}
+}
+
+func (q *sendQueue) push(chatID int64, job func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs, running := q.chats[chatID]
+	q.chats[chatID] = append(jobs, job)
+	if !running {
+		go q.drain(chatID)
+	}
+}
+
+func (q *sendQueue) drain(chatID int64) {
+	for {
+		q.mu.Lock()
+		jobs := q.chats[chatID]
+		if len(jobs) == 0 {
+			delete(q.chats, chatID)
+			q.mu.Unlock()
+			return
+		}
+		job := jobs[0]
+		q.chats[chatID] = jobs[1:]
+		q.mu.Unlock()
+		job()
+	}
+}
+
+func newSendThrottler(global, chat, group RateLimit) *sendThrottler {
+	return &sendThrottler{
+		global:     newGCRA(global),
+		chats:      make(map[int64]*gcra),
+		chatLimit:  chat,
+		groupLimit: group,
+	}
+}
+
+func (t *sendThrottler) wait(ctx context.Context, chatID int64) error {
+	delay := t.reserve(chatID)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	return sleepContext(ctx, delay)
+}
+
+func (t *sendThrottler) reserve(chatID int64) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.reservations++
+	if t.reservations%throttlerSweepInterval == 0 {
+		for id, chat := range t.chats {
+			if chat.tat.Before(now) {
+				delete(t.chats, id)
+			}
+		}
+	}
+	return max(t.global.reserve(now), t.getChat(chatID).reserve(now))
+}
+
+// penalize delays the chat and the global limiter too, as a flood limit might be for the whole bot
+func (t *sendThrottler) penalize(chatID int64, retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.global.penalize(now, retryAfter)
+	t.getChat(chatID).penalize(now, retryAfter)
+}
+
+func (t *sendThrottler) getChat(chatID int64) *gcra {
+	chat := t.chats[chatID]
+	if chat == nil {
+		// group and channel IDs are negative
+		if chatID < 0 {
+			chat = newGCRA(t.groupLimit)
+		} else {
+			chat = newGCRA(t.chatLimit)
+		}
+		t.chats[chatID] = chat
+	}
+	return chat
+}
+
+func newGCRA(limit RateLimit) *gcra {
+	if limit.Rate <= 0 || limit.Per <= 0 {
+		return &gcra{}
+	}
+	interval := limit.Per / time.Duration(limit.Rate)
+	return &gcra{
+		interval:  interval,
+		tolerance: interval * time.Duration(max(limit.Burst, 1)-1),
+	}
+}
+
+func (g *gcra) penalize(now time.Time, retryAfter time.Duration) {
+	if g.interval == 0 {
+		return
+	}
+	if tat := now.Add(retryAfter + g.tolerance); tat.After(g.tat) {
+		g.tat = tat
+	}
+}
+
+func (g *gcra) reserve(now time.Time) time.Duration {
+	if g.interval == 0 {
+		return 0
+	}
+	tat := g.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	g.tat = tat.Add(g.interval)
+	return tat.Sub(now) - g.tolerance
+}
+
+// request queues something to be sent to a chat respecting the rate limits and retries on failure.
+// Requests that upload from a reader can't be repeated, so they should be marked as not retryable.
+func (bot *Bot) request(ctx context.Context, chatID int64, retryable bool, send func() error) error {
+	done := make(chan error, 1)
+	bot.sendQueue.push(chatID, func() {
+		done <- bot.requestNow(ctx, chatID, retryable, send)
+	})
+	var err error
+	yieldWorker(ctx, func() {
+		err = <-done
+	})
+	return err
+}
+
+func (bot *Bot) requestNow(ctx context.Context, chatID int64, retryable bool, send func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := bot.throttler.wait(ctx, chatID); err != nil {
+			return err
+		}
+		err := send()
+		if err == nil {
+			return nil
+		}
+		delay, ok := getRetryDelay(err, attempt)
+		if !ok || !retryable || attempt >= bot.sendRetries {
+			return err
+		}
+		if tgErr, isTgErr := err.(*tgbotapi.Error); isTgErr && tgErr.RetryAfter > 0 {
+			bot.throttler.penalize(chatID, delay)
+		}
+		bot.logger.Warn("retrying failed request",
+			slog.Int64("chatID", chatID),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			slog.Any("err", err))
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func getRetryDelay(err error, attempt int) (time.Duration, bool) {
+	if tgErr, ok := err.(*tgbotapi.Error); ok {
+		if tgErr.RetryAfter > 0 {
+			return time.Duration(tgErr.RetryAfter) * time.Second, true
+		}
+		if tgErr.Code < 500 {
+			return 0, false
+		}
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return 0, false
+	}
+	// the shift is capped before shifting, as it would overflow after a few dozen attempts
+	backoff := min(time.Second<<min(attempt, 5), time.Second*30)
+	jitter := time.Duration(rand.Int63n(int64(backoff / 2)))
+	return backoff + jitter, true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,114 @@
+package botkit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestGCRA(t *testing.T) {
+	type step struct {
+		at        time.Duration
+		penalty   time.Duration
+		wantDelay time.Duration
+	}
+	tests := []struct {
+		name  string
+		limit RateLimit
+		steps []step
+	}{
+		{
+			name:  "burst then one per interval",
+			limit: RateLimit{Rate: 1, Per: time.Second, Burst: 3},
+			steps: []step{
+				{at: 0, wantDelay: -2 * time.Second},
+				{at: 0, wantDelay: -time.Second},
+				{at: 0, wantDelay: 0},
+				{at: 0, wantDelay: time.Second},
+				{at: 0, wantDelay: 2 * time.Second},
+			},
+		},
+		{
+			name:  "refill",
+			limit: RateLimit{Rate: 1, Per: time.Second, Burst: 2},
+			steps: []step{
+				{at: 0, wantDelay: -time.Second},
+				{at: 0, wantDelay: 0},
+				{at: 0, wantDelay: time.Second},
+				{at: 1500 * time.Millisecond, wantDelay: 500 * time.Millisecond},
+				{at: 10 * time.Second, wantDelay: -time.Second},
+				{at: 10 * time.Second, wantDelay: 0},
+			},
+		},
+		{
+			name:  "group limit",
+			limit: defaultGroupSendRateLimit,
+			steps: []step{
+				{at: 0, wantDelay: -6 * time.Second},
+				{at: 0, wantDelay: -3 * time.Second},
+				{at: 0, wantDelay: 0},
+				{at: 0, wantDelay: 3 * time.Second},
+				{at: time.Minute, wantDelay: -6 * time.Second},
+			},
+		},
+		{
+			name:  "penalty",
+			limit: RateLimit{Rate: 1, Per: time.Second, Burst: 3},
+			steps: []step{
+				{at: 0, wantDelay: -2 * time.Second},
+				{at: 0, penalty: 5 * time.Second},
+				{at: 0, wantDelay: 5 * time.Second},
+				{at: 6 * time.Second, wantDelay: 0},
+				{at: 20 * time.Second, wantDelay: -2 * time.Second},
+			},
+		},
+		{
+			name:  "disabled",
+			limit: RateLimit{},
+			steps: []step{
+				{at: 0},
+				{at: 0, penalty: time.Minute},
+				{at: 0},
+			},
+		},
+	}
+	start := time.Now()
+	for _, tt := range tests {
+		g := newGCRA(tt.limit)
+		for i, s := range tt.steps {
+			now := start.Add(s.at)
+			if s.penalty > 0 {
+				g.penalize(now, s.penalty)
+				continue
+			}
+			if delay := g.reserve(now); delay != s.wantDelay {
+				t.Errorf("%s: step %d delay = %v, want %v", tt.name, i, delay, s.wantDelay)
+			}
+		}
+	}
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		err      error
+		attempt  int
+		min, max time.Duration
+		ok       bool
+	}{
+		{err: &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}}, min: 7 * time.Second, max: 7 * time.Second, ok: true},
+		{err: &tgbotapi.Error{Code: 400}},
+		{err: &tgbotapi.Error{Code: 502}, min: time.Second, max: 1500 * time.Millisecond, ok: true},
+		{err: errors.New("connection reset"), attempt: 2, min: 4 * time.Second, max: 6 * time.Second, ok: true},
+		{err: errors.New("connection reset"), attempt: 5, min: 30 * time.Second, max: 45 * time.Second, ok: true},
+		{err: errors.New("connection reset"), attempt: 34, min: 30 * time.Second, max: 45 * time.Second, ok: true},
+		{err: errors.New("connection reset"), attempt: 100, min: 30 * time.Second, max: 45 * time.Second, ok: true},
+	}
+	for _, tt := range tests {
+		delay, ok := getRetryDelay(tt.err, tt.attempt)
+		if ok != tt.ok || delay < tt.min || delay > tt.max {
+			t.Errorf("getRetryDelay(%v, %d) = %v, %v, want %v-%v, %v", tt.err, tt.attempt, delay, ok, tt.min, tt.max, tt.ok)
+		}
+	}
+}
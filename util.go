@@ -1,6 +1,7 @@
 package botkit
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -15,9 +16,13 @@ type lazyDownloader struct {
 	init   func() (io.ReadCloser, error)
 }
 
-func newLazyDownloader(url string) *lazyDownloader {
+func newLazyDownloader(ctx context.Context, url string) *lazyDownloader {
 	init := func() (io.ReadCloser, error) {
-		resp, err := http.Get(url)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file url")
+		}
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			return nil, errors.Unwrap(err) // try not to leak url with the bot token
 		}
@@ -83,6 +88,38 @@ func getFileIDsFromMessage(msg *tgbotapi.Message) (ids []string) {
 	return
 }
 
+func getMessageText(msg *tgbotapi.Message) string {
+	if len(msg.Text) > 0 {
+		return msg.Text
+	}
+	return msg.Caption
+}
+
+func getUpdateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.EditedMessage != nil:
+		return update.EditedMessage.Chat.ID
+	case update.ChannelPost != nil:
+		return update.ChannelPost.Chat.ID
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID
+	case update.ChatMember != nil:
+		return update.ChatMember.Chat.ID
+	case update.PollAnswer != nil:
+		return update.PollAnswer.User.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
 type wrapperDialogMessage struct {
 	c tgbotapi.Chattable
 }
@@ -98,16 +135,17 @@ func (m *wrapperDialogMessage) toChattable(*Dialog) tgbotapi.Chattable {
 func (*wrapperDialogMessage) setMessageID(int) {
 }
 
-type wrappedReader struct {
+// contextReader also hides the Close method of the wrapped reader from tgbotapi
+type contextReader struct {
 	io.Reader
+	ctx context.Context
 }
 
-func slogMessage(msg *tgbotapi.Message) slog.Attr {
-	return slog.Group("message",
-		slog.Int("messageID", msg.MessageID),
-		slog.Int64("chatID", msg.Chat.ID),
-		slog.Int64("userID", msg.From.ID),
-		slog.String("text", msg.Text))
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
 }
 
 func slogCallbackQuery(q *tgbotapi.CallbackQuery) slog.Attr {
@@ -0,0 +1,140 @@
+package botkit
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+type WebhookOption func(*webhookOptions)
+
+type webhookOptions struct {
+	url            string
+	certFile       string
+	keyFile        string
+	uploadCert     bool
+	maxConnections int
+	dropPending    bool
+}
+
+func WithWebhookURL(url string) WebhookOption {
+	return func(wo *webhookOptions) {
+		wo.url = url
+	}
+}
+
+func WithWebhookTLS(certFile, keyFile string) WebhookOption {
+	return func(wo *webhookOptions) {
+		wo.certFile = certFile
+		wo.keyFile = keyFile
+	}
+}
+
+func WithSelfSignedCert() WebhookOption {
+	return func(wo *webhookOptions) {
+		wo.uploadCert = true
+	}
+}
+
+func WithMaxConnections(maxConnections int) WebhookOption {
+	return func(wo *webhookOptions) {
+		wo.maxConnections = maxConnections
+	}
+}
+
+func WithDropPendingUpdates() WebhookOption {
+	return func(wo *webhookOptions) {
+		wo.dropPending = true
+	}
+}
+
+func (bot *Bot) RunWebhook(addr, path string, opts ...WebhookOption) error {
+	var wo webhookOptions
+	for _, opt := range opts {
+		opt(&wo)
+	}
+
+	if len(wo.url) > 0 {
+		if err := bot.setWebhook(&wo); err != nil {
+			return err
+		}
+	}
+
+	bot.setCommandMenu()
+
+	mux := http.NewServeMux()
+	mux.Handle(path, bot)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	bot.mu.Lock()
+	if bot.closed {
+		bot.mu.Unlock()
+		return nil
+	}
+	bot.server = srv
+	bot.mu.Unlock()
+
+	var err error
+	if len(wo.certFile) > 0 {
+		err = srv.ListenAndServeTLS(wo.certFile, wo.keyFile)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (bot *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(bot.webhookSecret) > 0 {
+		token := r.Header.Get(webhookSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(bot.webhookSecret)) != 1 {
+			bot.logger.Warn("webhook request with invalid secret token", slog.String("remoteAddr", r.RemoteAddr))
+			http.Error(w, "invalid secret token", http.StatusForbidden)
+			return
+		}
+	}
+	update, err := bot.api.HandleUpdate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// telegram redelivers updates that are not acknowledged with a 2xx status
+	if !bot.dispatchUpdate(*update) {
+		http.Error(w, "bot is shutting down", http.StatusServiceUnavailable)
+	}
+}
+
+func (bot *Bot) setWebhook(wo *webhookOptions) error {
+	params := make(tgbotapi.Params)
+	params["url"] = wo.url
+	params.AddNonEmpty("secret_token", bot.webhookSecret)
+	params.AddNonZero("max_connections", wo.maxConnections)
+	params.AddBool("drop_pending_updates", wo.dropPending)
+	if allowedUpdates := bot.getAllowedUpdates(); len(allowedUpdates) > 0 {
+		if err := params.AddInterface("allowed_updates", allowedUpdates); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if wo.uploadCert {
+		if len(wo.certFile) == 0 {
+			return fmt.Errorf("self-signed certificate requested without TLS cert file")
+		}
+		files := []tgbotapi.RequestFile{{Name: "certificate", Data: tgbotapi.FilePath(wo.certFile)}}
+		_, err = bot.api.UploadFiles("setWebhook", params, files)
+	} else {
+		_, err = bot.api.MakeRequest("setWebhook", params)
+	}
+	return err
+}